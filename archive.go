@@ -0,0 +1,237 @@
+package selfupdater
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+)
+
+// WithArchiveBinaryName sets the name of the binary to extract when the release asset is an archive (`.tar.gz`, `.tgz`, `.tar.xz`, `.txz` or `.zip`).
+// It defaults to the repository name.
+func WithArchiveBinaryName(name string) UpdaterOpts {
+	return func(u *Updater) {
+		u.binaryName = name
+	}
+}
+
+type archiveKind int
+
+const (
+	archiveNone archiveKind = iota
+	archiveTarGz
+	archiveTarXz
+	archiveZip
+	// single compressed binaries, like `myapp_linux-amd64.gz`.
+	archiveGz
+	archiveXz
+)
+
+func archiveKindOf(name string) archiveKind {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return archiveTarGz
+	case strings.HasSuffix(name, ".tar.xz"), strings.HasSuffix(name, ".txz"):
+		return archiveTarXz
+	case strings.HasSuffix(name, ".zip"):
+		return archiveZip
+	case strings.HasSuffix(name, ".gz"):
+		return archiveGz
+	case strings.HasSuffix(name, ".xz"):
+		return archiveXz
+	default:
+		return archiveNone
+	}
+}
+
+func (u *Updater) archiveBinaryName() string {
+	if u.binaryName != "" {
+		return u.binaryName
+	}
+	if u.managed {
+		return strings.TrimSuffix(filepath.Base(u.targetPath), ".exe")
+	}
+	return u.Repo
+}
+
+// isArchivedBinary tells if the archive entry at entryPath is the binary to extract, whatever the directory it is nested in.
+func (u *Updater) isArchivedBinary(entryPath string) bool {
+	base := path.Base(entryPath)
+	name := u.archiveBinaryName()
+	return base == name || base == name+".exe"
+}
+
+// extractBinary replaces the downloaded archive with the binary it contains, or the compressed binary with the decompressed one.
+// It is a no-op if the asset is not an archive, unless it was downloaded in memory: it is then written as is.
+// The binary is extracted in the download directory, or next to the binary to replace for an in-memory download.
+func (u *Updater) extractBinary() error {
+	kind := archiveKindOf(u.assetName)
+	if kind == archiveNone && u.memAsset == nil {
+		return nil
+	}
+
+	var (
+		binPath string
+		err     error
+	)
+	if u.memAsset != nil {
+		binPath, err = u.memoryStagingPath()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInstallFailed, err)
+		}
+	} else {
+		// the name of an archive always differs from the one of the binary it holds, by its extension.
+		binPath = filepath.Join(u.tmpDir, u.archiveBinaryName())
+	}
+
+	err = u.writeBinary(binPath, kind)
+	if u.memAsset != nil {
+		u.memAsset = nil
+	} else {
+		u.fsys.Remove(u.tmpPath)
+	}
+	if err != nil {
+		u.fsys.Remove(binPath)
+		return fmt.Errorf("%w: failed to extract binary from %s -> %w", ErrInstallFailed, u.assetName, err)
+	}
+
+	u.tmpPath = binPath
+
+	return nil
+}
+
+// writeBinary writes the binary of the downloaded asset, of the given kind, to binPath.
+func (u *Updater) writeBinary(binPath string, kind archiveKind) error {
+	src, err := u.openDownloaded()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := u.fsys.Create(binPath, false, 0755)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case archiveTarGz, archiveTarXz:
+		err = u.extractTar(src, dst, kind)
+	case archiveZip:
+		err = u.extractZip(src, dst)
+	case archiveGz, archiveXz:
+		err = decompress(src, dst, kind)
+	default:
+		_, err = io.Copy(dst, src)
+	}
+
+	return errors.Join(err, dst.Close())
+}
+
+// decompressor returns the reader decompressing r for the given archive kind.
+func decompressor(r io.Reader, kind archiveKind) (io.Reader, error) {
+	switch kind {
+	case archiveTarGz, archiveGz:
+		return gzip.NewReader(r)
+	case archiveTarXz, archiveXz:
+		return xz.NewReader(r)
+	default:
+		return r, nil
+	}
+}
+
+// decompress writes the decompressed binary to dst.
+func decompress(src io.Reader, dst io.Writer, kind archiveKind) error {
+	r, err := decompressor(src, kind)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, r)
+	return err
+}
+
+func (u *Updater) extractTar(src io.Reader, dst io.Writer, kind archiveKind) error {
+	r, err := decompressor(src, kind)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !u.isArchivedBinary(hdr.Name) {
+			continue
+		}
+
+		_, err = io.Copy(dst, tr)
+		return err
+	}
+
+	return fmt.Errorf("binary %s not found in archive", u.archiveBinaryName())
+}
+
+// extractZip needs random access to the archive, so src is read in memory unless it is already.
+func (u *Updater) extractZip(src io.Reader, dst io.Writer) error {
+	var (
+		at   io.ReaderAt
+		size int64
+	)
+	switch r := src.(type) {
+	case *memoryAsset:
+		at, size = r, r.Size()
+	case interface {
+		io.ReaderAt
+		Stat() (fs.FileInfo, error)
+	}:
+		info, err := r.Stat()
+		if err != nil {
+			return err
+		}
+		at, size = r, info.Size()
+	default:
+		data, err := io.ReadAll(src)
+		if err != nil {
+			return err
+		}
+		at, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	zr, err := zip.NewReader(at, size)
+	if err != nil {
+		return err
+	}
+
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || !u.isArchivedBinary(zf.Name) {
+			continue
+		}
+
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(dst, rc)
+		rc.Close()
+		return err
+	}
+
+	return fmt.Errorf("binary %s not found in archive", u.archiveBinaryName())
+}
@@ -0,0 +1,141 @@
+package selfupdater
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// WithArch forces the architecture of the assets to match instead of runtime.GOARCH, like `arm64` or `armv6`.
+// For 32-bit ARM, the version (`armv6`, `armv7`) is detected otherwise, and plain `arm` matches any ARM asset.
+func WithArch(arch string) UpdaterOpts {
+	return func(u *Updater) {
+		u.goarch, u.goarm = arch, ""
+		if version, ok := strings.CutPrefix(arch, "armv"); ok {
+			u.goarch, u.goarm = "arm", version
+		}
+		u.platform = fmt.Sprintf("%s-%s", u.goos, arch)
+	}
+}
+
+// detectARM returns the 32-bit ARM version of the system (`6`, `7`, ...), from /proc/cpuinfo or the GOARM the binary was built with.
+// It returns an empty string if it can't be determined or the binary isn't built for ARM.
+func detectARM() string {
+	if runtime.GOARCH != "arm" {
+		return ""
+	}
+
+	if f, err := os.Open("/proc/cpuinfo"); err == nil {
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			key, value, ok := strings.Cut(scanner.Text(), ":")
+			if !ok || strings.TrimSpace(key) != "CPU architecture" {
+				continue
+			}
+
+			// a 64-bit CPU runs 32-bit binaries up to armv7.
+			if version, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+				return strconv.Itoa(min(version, 7))
+			}
+		}
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "GOARM" && setting.Value != "" {
+				return setting.Value[:1]
+			}
+		}
+	}
+
+	return ""
+}
+
+// assetARM returns the ARM version an asset is built for, from its tokens: `armv7` or `armhf` for 7, `armv6` or `armel` for 6.
+// It returns an empty string for a plain `arm` asset, and false if the asset isn't a 32-bit ARM one.
+func assetARM(tokens []string) (string, bool) {
+	for _, t := range tokens {
+		switch {
+		case t == "arm":
+			return "", true
+		case t == "armhf":
+			return "7", true
+		case t == "armel":
+			return "6", true
+		case strings.HasPrefix(t, "armv"):
+			version := strings.TrimPrefix(t, "armv")
+			if _, err := strconv.Atoi(version); err == nil {
+				return version, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// matchARM accepts the assets a 32-bit ARM system can run: the ones built for its version or an older one, and plain `arm` ones.
+func (u *Updater) matchARM(tokens []string) bool {
+	version, ok := assetARM(tokens)
+	if !ok {
+		return false
+	}
+
+	if version == "" || u.goarm == "" {
+		return true
+	}
+
+	// versions are compared as numbers, `armv10` is newer than `armv7`.
+	n, err := strconv.Atoi(version)
+	goarm, errGoarm := strconv.Atoi(u.goarm)
+	if err != nil || errGoarm != nil {
+		return version == u.goarm
+	}
+	return n <= goarm
+}
+
+// armRank orders ARM assets from the best fitting: the exact version first, then older versions from the newest, then plain `arm` ones.
+// When the version of the system is unknown, plain `arm` assets come first, then the oldest versions, the most likely to run.
+func (u *Updater) armRank(ra *github.ReleaseAsset) int {
+	version, _ := assetARM(assetTokens(ra.GetName()))
+	n, _ := strconv.Atoi(version)
+	switch {
+	case u.goarm == "" && version == "":
+		return 0
+	case u.goarm == "":
+		return n
+	case version == "":
+		return 100
+	case version == u.goarm:
+		return 0
+	default:
+		return 100 - n
+	}
+}
+
+// armPlatform names the 32-bit ARM version matched, like `armv7`, or `arm` if it is unknown.
+func (u *Updater) armPlatform() string {
+	if u.goarm == "" {
+		return "arm"
+	}
+	return "armv" + u.goarm
+}
+
+// preferARMVersion sorts the candidates so that the asset built for the ARM version of the system comes first.
+func (u *Updater) preferARMVersion(candidates []*github.ReleaseAsset) {
+	if u.goarch != "arm" || u.assetMatcher != nil || u.assetPattern != nil {
+		return
+	}
+
+	slices.SortStableFunc(candidates, func(a, b *github.ReleaseAsset) int {
+		return u.armRank(a) - u.armRank(b)
+	})
+}
@@ -0,0 +1,57 @@
+package selfupdater
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+func armAssets(names ...string) []*github.ReleaseAsset {
+	assets := make([]*github.ReleaseAsset, 0, len(names))
+	for _, name := range names {
+		assets = append(assets, &github.ReleaseAsset{Name: github.String(name)})
+	}
+	return assets
+}
+
+func TestMatchARM(t *testing.T) {
+	for _, test := range []struct {
+		arch, asset string
+		expected    bool
+	}{
+		{"armv7", "repo_linux_armv6", true},
+		{"armv7", "repo_linux_armv7", true},
+		{"armv6", "repo_linux_armv7", false},
+		{"armv10", "repo_linux_armv7", true},
+		{"armv7", "repo_linux_armv10", false},
+		{"armv7", "repo_linux_arm", true},
+		{"arm", "repo_linux_armv7", true},
+		{"armv7", "repo_linux_arm64", false},
+	} {
+		u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", test.arch))
+		if got := u.matchARM(assetTokens(test.asset)); got != test.expected {
+			t.Errorf("matchARM(%s) on %s = %t, expected %t", test.asset, test.arch, got, test.expected)
+		}
+	}
+}
+
+func TestPreferARMVersion(t *testing.T) {
+	for _, test := range []struct {
+		arch     string
+		expected []string
+	}{
+		{"armv7", []string{"repo_armv7", "repo_armv6", "repo_armv5", "repo_arm"}},
+		{"arm", []string{"repo_arm", "repo_armv5", "repo_armv6", "repo_armv7"}},
+	} {
+		u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", test.arch))
+
+		candidates := armAssets("repo_armv6", "repo_armv7", "repo_arm", "repo_armv5")
+		u.preferARMVersion(candidates)
+
+		if got := assetNames(candidates); !slices.Equal(got, test.expected) {
+			t.Errorf("candidates on %s sorted as %v, expected %v", test.arch, got, test.expected)
+		}
+	}
+}
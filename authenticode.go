@@ -0,0 +1,25 @@
+package selfupdater
+
+import "fmt"
+
+// WithAuthenticodeVerification makes the [Updater] check, on windows, that the downloaded binary has a valid Authenticode signature
+// whose signer certificate has the given subject (its common name, like `My Company Ltd`), before launching or installing it.
+// The certificate chain is checked with the WinTrust API, revocation included. It is a no-op on other systems.
+func WithAuthenticodeVerification(expectedSubject string) UpdaterOpts {
+	return func(u *Updater) {
+		u.authenticodeSubject = expectedSubject
+	}
+}
+
+func (u *Updater) verifyAuthenticode() error {
+	if u.authenticodeSubject == "" {
+		return nil
+	}
+
+	err := verifyAuthenticode(u.tmpPath, u.authenticodeSubject)
+	if err != nil {
+		return fmt.Errorf("%w: authenticode -> %w", ErrSignatureVerification, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package selfupdater
+
+// verifyAuthenticode is a no-op outside windows, binaries have no Authenticode signature.
+func verifyAuthenticode(string, string) error {
+	return nil
+}
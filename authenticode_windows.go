@@ -0,0 +1,96 @@
+//go:build windows
+
+package selfupdater
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// CMSG_SIGNER_CERT_INFO_PARAM, to get the issuer and serial number of the signer certificate.
+const cmsgSignerCertInfoParam = 7
+
+var (
+	crypt32              = windows.NewLazySystemDLL("crypt32.dll")
+	procCryptMsgGetParam = crypt32.NewProc("CryptMsgGetParam")
+	procCryptMsgClose    = crypt32.NewProc("CryptMsgClose")
+)
+
+// verifyAuthenticode checks the Authenticode signature of filePath with WinVerifyTrust, then the subject of its signer certificate.
+func verifyAuthenticode(filePath, expectedSubject string) error {
+	path, err := windows.UTF16PtrFromString(filePath)
+	if err != nil {
+		return err
+	}
+
+	data := &windows.WinTrustData{
+		Size:             uint32(unsafe.Sizeof(windows.WinTrustData{})),
+		UIChoice:         windows.WTD_UI_NONE,
+		RevocationChecks: windows.WTD_REVOKE_WHOLECHAIN,
+		UnionChoice:      windows.WTD_CHOICE_FILE,
+		StateAction:      windows.WTD_STATEACTION_VERIFY,
+		FileOrCatalogOrBlobOrSgnrOrCert: unsafe.Pointer(&windows.WinTrustFileInfo{
+			Size:     uint32(unsafe.Sizeof(windows.WinTrustFileInfo{})),
+			FilePath: path,
+		}),
+	}
+	err = windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+	data.StateAction = windows.WTD_STATEACTION_CLOSE
+	_ = windows.WinVerifyTrustEx(windows.InvalidHWND, &windows.WINTRUST_ACTION_GENERIC_VERIFY_V2, data)
+	if err != nil {
+		return fmt.Errorf("invalid signature of %s -> %w", filePath, err)
+	}
+
+	subject, err := signerSubject(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the signer of %s -> %w", filePath, err)
+	}
+
+	if subject != expectedSubject {
+		return fmt.Errorf("%s is signed by %q, expected %q", filePath, subject, expectedSubject)
+	}
+
+	return nil
+}
+
+// signerSubject returns the subject of the certificate that signed the file.
+// Only the signer certificate is looked at, not the other ones embedded in the signature.
+func signerSubject(path *uint16) (string, error) {
+	var (
+		encoding, contentType, formatType uint32
+		store, msg                        windows.Handle
+	)
+	err := windows.CryptQueryObject(windows.CERT_QUERY_OBJECT_FILE, unsafe.Pointer(path),
+		windows.CERT_QUERY_CONTENT_FLAG_PKCS7_SIGNED_EMBED, windows.CERT_QUERY_FORMAT_FLAG_BINARY, 0,
+		&encoding, &contentType, &formatType, &store, &msg, nil)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CertCloseStore(store, 0)
+	defer procCryptMsgClose.Call(uintptr(msg))
+
+	var size uint32
+	if r, _, err := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerCertInfoParam, 0, 0, uintptr(unsafe.Pointer(&size))); r == 0 {
+		return "", err
+	}
+
+	// a []uint64 keeps the CERT_INFO aligned.
+	buf := make([]uint64, (size+7)/8)
+	if r, _, err := procCryptMsgGetParam.Call(uintptr(msg), cmsgSignerCertInfoParam, 0, uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&size))); r == 0 {
+		return "", err
+	}
+
+	cert, err := windows.CertFindCertificateInStore(store, encoding, 0, windows.CERT_FIND_SUBJECT_CERT, unsafe.Pointer(&buf[0]), nil)
+	if err != nil {
+		return "", err
+	}
+	defer windows.CertFreeCertificateContext(cert)
+
+	n := windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, nil, 0)
+	name := make([]uint16, n)
+	windows.CertGetNameString(cert, windows.CERT_NAME_SIMPLE_DISPLAY_TYPE, 0, nil, &name[0], n)
+
+	return windows.UTF16ToString(name), nil
+}
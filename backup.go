@@ -0,0 +1,127 @@
+package selfupdater
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// WithBackupRetention makes the [Updater] keep the last n replaced binaries, named after their version
+// (`<exe>-1.2.3-old`, `<exe>-1.2.3.old` on windows), instead of a single `-old` one. Backups of older versions are pruned.
+// Use [Updater.RollbackTo] to restore one of them.
+func WithBackupRetention(n int) UpdaterOpts {
+	return func(u *Updater) {
+		u.backupRetention = n
+	}
+}
+
+func (u *Updater) backupSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".old"
+	}
+	return "-old"
+}
+
+func (u *Updater) backupPath(v semver.Version) string {
+	return fmt.Sprintf("%s-%s%s", u.exePath, v, u.backupSuffix())
+}
+
+// listBackups returns the versions of the backups kept next to the binary, from the newest to the oldest.
+func (u *Updater) listBackups() ([]semver.Version, error) {
+	prefix := filepath.Base(u.exePath) + "-"
+	entries, err := u.fsys.ReadDir(filepath.Dir(u.exePath))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]semver.Version, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, u.backupSuffix()) {
+			continue
+		}
+		v, err := semver.Parse(strings.TrimSuffix(strings.TrimPrefix(name, prefix), u.backupSuffix()))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	slices.SortFunc(versions, func(a, b semver.Version) int {
+		return compareVersions(b, a)
+	})
+
+	return versions, nil
+}
+
+// pruneBackups removes the backups beyond the retention, the highest versions are kept.
+// It is best effort: the update or rollback is complete by then, failures are only logged.
+func (u *Updater) pruneBackups() {
+	if u.backupRetention <= 0 {
+		return
+	}
+
+	versions, err := u.listBackups()
+	if err != nil {
+		u.logger.Warn("failed to list old binary backups", "path", filepath.Dir(u.exePath), "error", err)
+		return
+	}
+	if len(versions) <= u.backupRetention {
+		return
+	}
+
+	for _, v := range versions[u.backupRetention:] {
+		u.logger.Debug("pruning old binary backup", "version", v.String())
+		if err := u.fsys.Remove(u.backupPath(v)); err != nil {
+			u.logger.Warn("failed to prune old binary backup", "path", u.backupPath(v), "error", err)
+		}
+	}
+}
+
+// RollbackTo restores the backup of version v kept thanks to [WithBackupRetention] in place of the current binary,
+// which is backed up in turn. It returns [ErrBackupNotFound] if there is no backup for this version.
+func (u *Updater) RollbackTo(v semver.Version) error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return err
+	}
+	u.exePath = exePath
+
+	backup := u.backupPath(v)
+	if _, err := u.fsys.Stat(backup); err != nil {
+		return fmt.Errorf("%w: %s -> %w", ErrBackupNotFound, v, err)
+	}
+
+	// the backup is copied rather than moved so that it stays available.
+	restored := exePath + ".rollback"
+	if err := copyFile(u.fsys, backup, restored); err != nil {
+		return fmt.Errorf("%w: failed to copy backup %s -> %w", ErrRollbackFailed, backup, err)
+	}
+
+	current := u.backupPath(u.Current)
+	if err := moveFile(u.fsys, exePath, current); err != nil {
+		u.fsys.Remove(restored)
+		return fmt.Errorf("%w: failed to backup the current binary -> %w", ErrRollbackFailed, err)
+	}
+
+	if err := moveFile(u.fsys, restored, exePath); err != nil {
+		errBack := moveFile(u.fsys, current, exePath)
+		return fmt.Errorf("%w: failed to restore backup %s (%w) -> %w", ErrRollbackFailed, backup, errBack, err)
+	}
+
+	u.logger.Info("rolled back to backup", "version", v.String(), "from", u.Current.String())
+	u.Current = v
+	u.pruneBackups()
+
+	return nil
+}
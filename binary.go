@@ -0,0 +1,52 @@
+package selfupdater
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// executableMagics are the headers of the executable formats, by os.
+var executableMagics = map[string][][]byte{
+	"windows": {[]byte("MZ")},
+	"darwin": {
+		{0xfe, 0xed, 0xfa, 0xce}, {0xce, 0xfa, 0xed, 0xfe}, // 32-bit Mach-O
+		{0xfe, 0xed, 0xfa, 0xcf}, {0xcf, 0xfa, 0xed, 0xfe}, // 64-bit Mach-O
+		{0xca, 0xfe, 0xba, 0xbe}, // universal binary
+	},
+}
+
+// elfSystems are the systems using the ELF format.
+var elfSystems = []string{"linux", "freebsd", "netbsd", "openbsd", "dragonfly", "solaris", "illumos", "android", "aix"}
+
+// checkBinaryFormat makes sure filePath is an executable for goos (ELF, Mach-O or PE) rather than, say, an HTML error page.
+// Systems with another format are not checked.
+func checkBinaryFormat(fsys FileSystem, filePath, goos string) error {
+	magics, ok := executableMagics[goos]
+	if !ok && slices.Contains(elfSystems, goos) {
+		magics, ok = [][]byte{[]byte("\x7fELF")}, true
+	}
+	if !ok {
+		return nil
+	}
+
+	f, err := fsys.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("%w: failed to open %s -> %w", ErrInvalidBinary, filePath, err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: failed to read %s -> %w", ErrInvalidBinary, filePath, err)
+	}
+	header = header[:n]
+
+	if !slices.ContainsFunc(magics, func(magic []byte) bool { return bytes.HasPrefix(header, magic) }) {
+		return fmt.Errorf("%w: %s is not a %s executable (header %q)", ErrInvalidBinary, filePath, goos, header)
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package selfupdater
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/blang/semver"
+)
+
+// NewFromBuildInfo creates a new instance of Updater like [New], with the current version read from the build info of the binary:
+// the version of the main module, set by `go install module@version` (or by `go build` from a tagged VCS checkout since go 1.24).
+// If the binary has no usable version (like `(devel)` for a local build), every call to the Updater fails with [ErrUnknownVersion]
+// (see [Updater.Err]): use [New] with a version injected at link time (`-ldflags "-X main.version=..."`) instead.
+func NewFromBuildInfo(owner, repo string, options ...UpdaterOpts) *Updater {
+	current, err := buildInfoVersion()
+
+	u := New(owner, repo, current, options...)
+	if u.err == nil {
+		u.err = err
+	}
+
+	return u
+}
+
+func buildInfoVersion() (semver.Version, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return semver.Version{}, fmt.Errorf("%w: no build info in binary", ErrUnknownVersion)
+	}
+
+	v, err := semver.ParseTolerant(info.Main.Version)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("%w: main module version %q -> %w", ErrUnknownVersion, info.Main.Version, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,126 @@
+package selfupdater
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// WithCacheTTL makes [Updater.CheckLatest] reuse the latest release fetched less than ttl ago for the same repository,
+// instead of querying github each time. The cache is shared by every [Updater] of the process and safe for concurrent use.
+// It is bypassed by the updaters with their own way of picking or parsing releases, which can't be part of the cache key:
+// see [WithReleaseFilter], [WithVersionComparator] and [WithTagParser].
+func WithCacheTTL(ttl time.Duration) UpdaterOpts {
+	return func(u *Updater) {
+		u.cacheTTL = ttl
+	}
+}
+
+type cacheEntry struct {
+	release   *github.RepositoryRelease
+	version   semver.Version
+	fetchedAt time.Time
+	// source is the provider the release was found on (see [WithMirrors]), its asset IDs are only valid there.
+	source string
+}
+
+type releaseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+var latestCache = &releaseCache{entries: make(map[string]cacheEntry)}
+
+func (c *releaseCache) get(key string, ttl time.Duration) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.fetchedAt) > ttl {
+		return cacheEntry{}, false
+	}
+
+	return entry, true
+}
+
+func (c *releaseCache) set(key string, entry cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = entry
+}
+
+// providerSource identifies where provider fetches releases from.
+func providerSource(provider ReleaseProvider) string {
+	switch p := provider.(type) {
+	case *githubProvider:
+		return p.client.BaseURL.String() + "|" + p.owner + "/" + p.repo
+	case *GitLabProvider:
+		return p.baseURL() + "|" + p.Project
+	default:
+		return fmt.Sprintf("%T", p)
+	}
+}
+
+func (u *Updater) cacheKey() string {
+	provider := u.provider
+	if m, ok := provider.(*mirrorProvider); ok {
+		provider = m.providers[0]
+	}
+
+	return fmt.Sprintf("%s|%s/%s|prereleases=%t|drafts=%t|constraint=%s|policy=%d|current=%s", providerSource(provider), u.Owner, u.Repo, u.prereleases, u.drafts, u.constraint, u.policy, u.Current)
+}
+
+// recordCachedRelease hands a release served from the cache over to provider, if it needs it to download the assets (see [releaseRecorder]).
+func recordCachedRelease(provider ReleaseProvider, rel *github.RepositoryRelease) {
+	if recorder, ok := provider.(releaseRecorder); ok {
+		recorder.recordRelease(rel)
+	}
+}
+
+// cachedLatestRelease fetches the latest release, going through the cache if [WithCacheTTL] is set.
+func (u *Updater) cachedLatestRelease() (*github.RepositoryRelease, semver.Version, error) {
+	if u.cacheTTL <= 0 || u.releaseFilter != nil || u.newerVersion != nil || u.tagParser != nil {
+		return u.fetchLatestRelease()
+	}
+
+	key := u.cacheKey()
+	mirrors, _ := u.provider.(*mirrorProvider)
+	if entry, ok := latestCache.get(key, u.cacheTTL); ok {
+		if mirrors == nil {
+			recordCachedRelease(u.provider, entry.release)
+			return entry.release, entry.version, nil
+		}
+		// the release is only reused if it was found on one of the mirrors of this updater.
+		index := slices.IndexFunc(mirrors.providers, func(p ReleaseProvider) bool {
+			return providerSource(p) == entry.source
+		})
+		if index != -1 {
+			mirrors.record(index, entry.release)
+			recordCachedRelease(mirrors.providers[index], entry.release)
+			return entry.release, entry.version, nil
+		}
+	}
+
+	rel, latest, err := u.fetchLatestRelease()
+	if err != nil {
+		return nil, semver.Version{}, err
+	}
+
+	// a release still being uploaded is fetched again next time.
+	if len(rel.Assets) == 0 {
+		return rel, latest, nil
+	}
+
+	entry := cacheEntry{release: rel, version: latest, fetchedAt: time.Now(), source: providerSource(u.provider)}
+	if mirrors != nil {
+		entry.source = providerSource(mirrors.providers[mirrors.activeIndex()])
+	}
+	latestCache.set(key, entry)
+
+	return rel, latest, nil
+}
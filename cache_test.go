@@ -0,0 +1,101 @@
+package selfupdater
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// lookupProvider is a [fakeProvider] counting the latest release lookups.
+type lookupProvider struct {
+	*fakeProvider
+	lookups int
+}
+
+func (p *lookupProvider) LatestRelease(ctx context.Context) (*github.RepositoryRelease, error) {
+	p.lookups++
+	return p.fakeProvider.LatestRelease(ctx)
+}
+
+func TestCacheBypassedByCustomVersions(t *testing.T) {
+	for name, opt := range map[string]UpdaterOpts{
+		"tag parser": WithTagParser(func(tag string) (semver.Version, error) {
+			return semver.ParseTolerant(strings.TrimPrefix(tag, "release-"))
+		}),
+		"version comparator": WithVersionComparator(func(latest, current semver.Version) bool {
+			return latest.Patch > current.Patch
+		}),
+	} {
+		provider := &lookupProvider{fakeProvider: &fakeProvider{}}
+		provider.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": elfBinary("v2")})
+
+		repo := "cache-" + strings.ReplaceAll(name, " ", "-")
+		cached := New("owner", repo, semver.MustParse("1.0.0"), WithProvider(provider), WithCacheTTL(time.Hour))
+		if _, _, err := cached.latestRelease(); err != nil {
+			t.Fatal(err)
+		}
+
+		lookups := provider.lookups
+		for i := 0; i < 2; i++ {
+			u := New("owner", repo, semver.MustParse("1.0.0"), WithProvider(provider), WithCacheTTL(time.Hour), opt)
+			if _, _, err := u.latestRelease(); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+
+		if provider.lookups-lookups != 2 {
+			t.Errorf("%s: %d lookups, expected the cache to be bypassed", name, provider.lookups-lookups)
+		}
+	}
+}
+
+func TestCacheSharedByGitLabProviders(t *testing.T) {
+	var lookups atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/downloads/repo_linux_amd64" {
+			w.Write(elfBinary("v2"))
+			return
+		}
+
+		lookups.Add(1)
+		json.NewEncoder(w).Encode([]map[string]any{{
+			"tag_name": "v1.1.0",
+			"assets": map[string]any{"links": []map[string]any{
+				{"id": 7, "name": "repo_linux_amd64", "direct_asset_url": server.URL + "/downloads/repo_linux_amd64"},
+			}},
+		}})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		// every updater has its own provider, the second one never saw the release it gets from the cache.
+		provider := &GitLabProvider{Project: "group/cache-gitlab", BaseURL: server.URL}
+		u := New("group", "cache-gitlab", semver.MustParse("1.0.0"), WithProvider(provider), WithCacheTTL(time.Hour),
+			WithPlatform("linux", "amd64"))
+
+		path, err := u.DownloadTo(filepath.Join(dir, strconv.Itoa(i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := os.ReadFile(path); string(content) != string(elfBinary("v2")) {
+			t.Errorf("%s is %q, expected the release asset", path, content)
+		}
+	}
+
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("%d lookups, expected the release to be cached", n)
+	}
+}
@@ -0,0 +1,204 @@
+package selfupdater
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v59/github"
+	"golang.org/x/crypto/blake2b"
+)
+
+// checksumAlgos are the algorithms supported by [WithChecksumAlgo].
+var checksumAlgos = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+	"blake2b": func() hash.Hash {
+		h, _ := blake2b.New512(nil)
+		return h
+	},
+}
+
+// checksumFiles maps the conventional names of checksum assets to their algorithm.
+var checksumFiles = map[string]string{
+	"sha256sums": "sha256",
+	"sha512sums": "sha512",
+	"b2sums":     "blake2b",
+	"blake2sums": "blake2b",
+}
+
+// WithChecksumVerification enables the verification of the downloaded asset against the checksum published in the release.
+// The checksum asset is expected to be named `checksums.txt` (`<anything>_checksums.txt` also works), `SHA256SUMS`, `SHA512SUMS` or `B2SUMS`,
+// and to contain `<hash>  <filename>` lines. The algorithm is guessed from the name of the checksum asset, or the length of the hashes, see [WithChecksumAlgo].
+func WithChecksumVerification(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.checksum = enabled
+	}
+}
+
+// WithChecksumRequired defines what happens when checksum verification is enabled but the release doesn't provide a checksum for the asset.
+// If required is true, [Updater.Update] fails with [ErrChecksumNotFound], otherwise the verification is silently skipped.
+func WithChecksumRequired(required bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.checksumRequired = required
+	}
+}
+
+// WithChecksumAlgo sets the algorithm of the checksums published in the release: `sha256`, `sha512` or `blake2b` (BLAKE2b-512, like `b2sum`).
+// The checksum asset of that algorithm is preferred if the release has several. An unknown algorithm makes every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithChecksumAlgo(algo string) UpdaterOpts {
+	return func(u *Updater) {
+		u.checksumAlgo = strings.ToLower(algo)
+	}
+}
+
+func validateChecksumAlgo(algo string) error {
+	if _, ok := checksumAlgos[algo]; algo != "" && !ok {
+		return fmt.Errorf("%w: unsupported checksum algorithm %q", ErrInvalidOption, algo)
+	}
+
+	return nil
+}
+
+func isChecksumAsset(name string) bool {
+	lower := strings.ToLower(name)
+	_, ok := checksumFiles[lower]
+	return ok || strings.HasSuffix(lower, "checksums.txt")
+}
+
+// checksumAlgoOf returns the algorithm of a checksum asset, from its name or else the length of its hashes.
+// A 512 bits hash is expected to be SHA512 rather than BLAKE2b, unless set with [WithChecksumAlgo].
+func (u *Updater) checksumAlgoOf(name, sum string) string {
+	if algo, ok := checksumFiles[strings.ToLower(name)]; ok {
+		return algo
+	}
+
+	if u.checksumAlgo != "" {
+		return u.checksumAlgo
+	}
+
+	if len(sum) == 2*sha512.Size {
+		return "sha512"
+	}
+
+	return "sha256"
+}
+
+func (u *Updater) getChecksumAsset() *github.ReleaseAsset {
+	index := slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
+		return u.checksumAlgo != "" && checksumFiles[strings.ToLower(ra.GetName())] == u.checksumAlgo
+	})
+	if index == -1 {
+		index = slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
+			return isChecksumAsset(ra.GetName())
+		})
+	}
+
+	if index == -1 {
+		return nil
+	}
+
+	return u.assets[index]
+}
+
+func parseChecksums(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// a leading `*` means the file was hashed in binary mode.
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+
+	return sums, scanner.Err()
+}
+
+func (u *Updater) fetchChecksums(asset *github.ReleaseAsset) (map[string]string, error) {
+	reader, err := u.openAsset(asset.GetID())
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	sums, err := parseChecksums(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checksum asset %s -> %w", asset.GetName(), err)
+	}
+
+	return sums, nil
+}
+
+// downloadedHash returns the hex encoded hash of the downloaded release asset.
+func (u *Updater) downloadedHash(h hash.Hash) (string, error) {
+	f, err := u.openDownloaded()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func checksumNotFound(required bool, reason string) error {
+	if required {
+		return fmt.Errorf("%w: %s", ErrChecksumNotFound, reason)
+	}
+	return nil
+}
+
+func (u *Updater) verifyChecksum() error {
+	if !u.checksum {
+		return nil
+	}
+
+	return u.checkChecksum(u.checksumRequired)
+}
+
+// checkChecksum compares the checksum of the downloaded asset with the one published in the release.
+// When the release has no checksum for the asset, it fails only if required.
+func (u *Updater) checkChecksum(required bool) error {
+	asset := u.getChecksumAsset()
+	if asset == nil {
+		return checksumNotFound(required, "no checksum asset in release")
+	}
+
+	var sums map[string]string
+	err := u.retry(func() (err error) {
+		sums, err = u.fetchChecksums(asset)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	expected, ok := sums[u.assetName]
+	if !ok {
+		return checksumNotFound(required, fmt.Sprintf("no entry for %s in %s", u.assetName, asset.GetName()))
+	}
+
+	algo := u.checksumAlgoOf(asset.GetName(), expected)
+	actual, err := u.downloadedHash(checksumAlgos[algo]())
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum of downloaded release asset -> %w", err)
+	}
+
+	if actual != expected {
+		return fmt.Errorf("%w: %s expected %s %s, got %s", ErrChecksumMismatch, u.assetName, algo, expected, actual)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Cleanup removes the `-old` binary left by [Updater.Update] as well as the leftovers of failed downloads.
+// Call it once the new binary is confirmed to work, typically when the new version starts. Versioned backups
+// kept with [WithBackupRetention] are left untouched. It is safe to call when there is nothing to remove.
+func (u *Updater) Cleanup() error {
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return err
+	}
+	u.exePath = exePath
+
+	var errs []error
+	if err := u.fsys.Remove(u.singleOldPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("failed to remove old binary -> %w", err))
+	}
+
+	for _, dir := range u.tempDirCandidates() {
+		if err := removeAll(u.fsys, dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove temp download directory -> %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,22 @@
+package selfupdater
+
+import "fmt"
+
+// WithCodesignIdentity makes the [Updater] sign the new binary with `codesign --force --sign identity` on macOS before installing it,
+// so that Gatekeeper keeps running it once replaced (an ad-hoc signature is `-`). Whether set or not, the `com.apple.quarantine`
+// attribute is removed from the new binary on macOS. It is a no-op on other systems.
+func WithCodesignIdentity(identity string) UpdaterOpts {
+	return func(u *Updater) {
+		u.codesignIdentity = identity
+	}
+}
+
+// prepareMacBinary removes the quarantine attribute of the binary at binPath and signs it if enabled, on macOS.
+func (u *Updater) prepareMacBinary(binPath string) error {
+	err := prepareMacBinary(binPath, u.codesignIdentity)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInstallFailed, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+//go:build darwin
+
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const quarantineAttr = "com.apple.quarantine"
+
+// prepareMacBinary removes the quarantine attribute from the binary at binPath, then signs it with identity if not empty.
+func prepareMacBinary(binPath, identity string) error {
+	err := unix.Removexattr(binPath, quarantineAttr)
+	if err != nil && !errors.Is(err, unix.ENOATTR) {
+		return fmt.Errorf("failed to remove %s attribute -> %w", quarantineAttr, err)
+	}
+
+	if identity == "" {
+		return nil
+	}
+
+	output, err := exec.Command("codesign", "--force", "--sign", identity, binPath).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to codesign with %q: %s -> %w", identity, strings.TrimSpace(string(output)), err)
+	}
+
+	return nil
+}
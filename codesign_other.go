@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package selfupdater
+
+// prepareMacBinary is a no-op outside macOS, there is no quarantine nor Gatekeeper.
+func prepareMacBinary(string, string) error {
+	return nil
+}
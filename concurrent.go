@@ -0,0 +1,190 @@
+package selfupdater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+)
+
+var errNoRanges = errors.New("byte ranges are not supported")
+
+// partFile is the file an asset downloaded in parts is written to, at the offset of each part.
+type partFile interface {
+	io.WriterAt
+	io.Closer
+}
+
+// partDownloader is implemented by providers able to download a byte range of an asset.
+type partDownloader interface {
+	// DownloadAssetRange streams the bytes start to end (inclusive) of the asset, it tells whether the range was honored or the whole asset is streamed.
+	DownloadAssetRange(ctx context.Context, id int64, start, end int64) (io.ReadCloser, bool, error)
+}
+
+// WithConcurrentDownload makes the [Updater] download the release asset in the given number of byte ranges fetched in parallel,
+// which speeds up the download of large assets on fast connections.
+// The asset is downloaded in a single stream if the provider or the storage server doesn't support ranges.
+// A download in parts is not resumed, it starts over on retry (see [WithRetry]).
+func WithConcurrentDownload(parts int) UpdaterOpts {
+	return func(u *Updater) {
+		u.downloadParts = parts
+	}
+}
+
+// fetchAsset downloads the release asset to u.tmpPath, in parts if enabled and supported, or in memory (see [WithInMemoryDownload]).
+func (u *Updater) fetchAsset() error {
+	if u.inMemory {
+		return u.downloadToMemory()
+	}
+
+	if u.downloadParts > 1 {
+		err := u.downloadInParts()
+		if !errors.Is(err, errNoRanges) {
+			return err
+		}
+		u.logger.Debug("byte ranges not supported, downloading in a single stream", "asset", u.assetName)
+	}
+
+	return u.downloadAsset()
+}
+
+func (u *Updater) downloadInParts() error {
+	pd, ok := u.provider.(partDownloader)
+	if !ok || u.assetURL != "" || u.assetSize < int64(u.downloadParts) {
+		return errNoRanges
+	}
+
+	ctx, cancel := context.WithCancel(u.ctx)
+	defer cancel()
+
+	partSize := (u.assetSize + int64(u.downloadParts) - 1) / int64(u.downloadParts)
+
+	// the first part tells whether ranges are honored.
+	first, ranged, err := pd.DownloadAssetRange(ctx, u.assetID, 0, partSize-1)
+	if err != nil {
+		return fmt.Errorf("%w: failed to download release asset -> %w", ErrDownloadFailed, err)
+	}
+	if !ranged {
+		first.Close()
+		return errNoRanges
+	}
+
+	u.tmpPath = filepath.Join(u.tmpDir, u.assetName)
+	partPath := u.partPath()
+	u.logger.Info("downloading release asset in parts", "asset", u.assetName, "size", u.assetSize, "parts", u.downloadParts, "path", partPath)
+
+	created, err := u.fsys.Create(partPath, false, 0666)
+	if err != nil {
+		first.Close()
+		return fmt.Errorf("%w: failed to create temp downloaded release asset -> %w", ErrDownloadFailed, err)
+	}
+	f, ok := created.(partFile)
+	if !ok {
+		first.Close()
+		created.Close()
+		u.fsys.Remove(partPath)
+		return errNoRanges
+	}
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		downloaded int64
+		firstErr   error
+	)
+	count := func(n int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		downloaded += n
+		u.reportProgress(downloaded, u.assetSize)
+	}
+
+	reader := first
+	for start := int64(0); start < u.assetSize; start += partSize {
+		end := min(start+partSize, u.assetSize) - 1
+
+		wg.Add(1)
+		go func(reader io.ReadCloser, start, end int64) {
+			defer wg.Done()
+
+			err := u.downloadPart(ctx, pd, reader, f, start, end, count)
+			if err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(reader, start, end)
+		reader = nil
+	}
+	wg.Wait()
+
+	err = errors.Join(firstErr, f.Close())
+	if err == nil {
+		err = u.fsys.Rename(partPath, u.tmpPath)
+	}
+	if err == nil && !u.checksum {
+		// parts are reassembled, the checksum is checked even if verification is not enabled as long as the release provides it.
+		err = u.checkChecksum(false)
+	}
+	if err != nil {
+		u.fsys.Remove(partPath)
+		u.fsys.Remove(u.tmpPath)
+		u.logger.Debug("release asset download failed", "asset", u.assetName, "bytes", downloaded, "error", err)
+		return fmt.Errorf("%w: failed to download release asset in parts -> %w", ErrDownloadFailed, err)
+	}
+
+	u.logger.Info("release asset downloaded", "asset", u.assetName, "bytes", downloaded)
+	return nil
+}
+
+// downloadPart writes the bytes start to end of the release asset at the same offset in f.
+// reader is the already opened part, if any.
+func (u *Updater) downloadPart(ctx context.Context, pd partDownloader, reader io.ReadCloser, f io.WriterAt, start, end int64, count func(n int64)) error {
+	if reader == nil {
+		var (
+			ranged bool
+			err    error
+		)
+		reader, ranged, err = pd.DownloadAssetRange(ctx, u.assetID, start, end)
+		if err != nil {
+			return err
+		}
+		if !ranged {
+			reader.Close()
+			return fmt.Errorf("range %d-%d not honored", start, end)
+		}
+	}
+	defer reader.Close()
+
+	src := &countingReader{reader: &contextReader{ctx: ctx, reader: io.LimitReader(reader, end-start+1)}, count: count}
+	written, err := io.Copy(io.NewOffsetWriter(f, start), src)
+	if err != nil {
+		return err
+	}
+
+	if written != end-start+1 {
+		return fmt.Errorf("%w: part %d-%d is %d bytes", ErrIncompleteDownload, start, end, written)
+	}
+
+	return nil
+}
+
+// countingReader reports the number of bytes of each read.
+type countingReader struct {
+	reader io.Reader
+	count  func(n int64)
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.reader.Read(b)
+	if n > 0 {
+		c.count(int64(n))
+	}
+
+	return n, err
+}
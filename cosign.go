@@ -0,0 +1,450 @@
+package selfupdater
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CosignOptions configures the verification of cosign signatures (`cosign sign-blob`), see [WithCosignVerification].
+// Either PublicKey (signing with a key) or Roots (keyless signing) must be set.
+type CosignOptions struct {
+	// PublicKey is the PEM encoded public key the assets are signed with (`cosign.pub`).
+	PublicKey []byte
+	// Roots is the PEM encoded bundle of the certificate authorities (e.g. the Fulcio root and intermediate certificates)
+	// the keyless signing certificate, expected in a `<asset name>.pem` or `<asset name>.crt` asset, must chain to.
+	// Keyless signing requires Identity, Issuer, RekorURL and RekorPublicKey as well.
+	Roots []byte
+	// Identity is the expected subject (email or URI) of the keyless signing certificate, like
+	// `https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.2.3`.
+	Identity string
+	// Issuer is the expected OIDC issuer of the keyless signing certificate, like `https://token.actions.githubusercontent.com`.
+	Issuer string
+	// RekorURL, like `https://rekor.sigstore.dev`, makes the verification require an entry of the Rekor transparency log binding the signature,
+	// the certificate (or public key) and the asset digest. Keyless signing certificates are checked at the time the entry was logged.
+	RekorURL string
+	// RekorPublicKey is the PEM encoded public key of the Rekor instance, which signs the timestamp of its entries. It is required with RekorURL.
+	RekorPublicKey []byte
+}
+
+// the Fulcio certificate extensions holding the OIDC issuer, the first one is deprecated but still set.
+var (
+	oidFulcioIssuer   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidFulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// WithCosignVerification enables the verification of the cosign signature of the downloaded asset, expected in a `<asset name>.sig` asset of the same release.
+// A missing or invalid signature (or certificate, or transparency log entry) aborts the update with [ErrSignatureVerification].
+// Incomplete options (see [CosignOptions]) make every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithCosignVerification(opts CosignOptions) UpdaterOpts {
+	return func(u *Updater) {
+		u.cosign = &opts
+	}
+}
+
+func validateCosign(opts *CosignOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	switch {
+	case len(opts.PublicKey) == 0 && len(opts.Roots) == 0:
+		return fmt.Errorf("%w: cosign verification requires PublicKey or Roots", ErrInvalidOption)
+	case len(opts.PublicKey) == 0 && (opts.Identity == "" || opts.Issuer == "" || opts.RekorURL == ""):
+		return fmt.Errorf("%w: keyless cosign verification requires Identity, Issuer and RekorURL", ErrInvalidOption)
+	case opts.RekorURL != "" && len(opts.RekorPublicKey) == 0:
+		return fmt.Errorf("%w: cosign verification with RekorURL requires RekorPublicKey", ErrInvalidOption)
+	}
+
+	if opts.RekorURL != "" {
+		return validateRekor(opts.RekorURL, opts.RekorPublicKey)
+	}
+
+	return nil
+}
+
+// validateRekor checks the url and the public key of a Rekor instance.
+func validateRekor(rekorURL string, rekorKey []byte) error {
+	if _, err := url.ParseRequestURI(rekorURL); err != nil {
+		return fmt.Errorf("%w: malformed rekor url %q -> %w", ErrInvalidOption, rekorURL, err)
+	}
+	if _, err := parsePublicKey(rekorKey); err != nil {
+		return fmt.Errorf("%w: invalid rekor public key -> %w", ErrInvalidOption, err)
+	}
+
+	return nil
+}
+
+// decodeCosignAsset returns the content of a signature or certificate asset, which cosign writes base64 encoded.
+func decodeCosignAsset(content []byte) []byte {
+	content = bytes.TrimSpace(content)
+	if decoded, err := base64.StdEncoding.DecodeString(string(content)); err == nil {
+		return decoded
+	}
+
+	return content
+}
+
+func parsePublicKey(key []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key")
+	}
+
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func verifyDigestSignature(pub crypto.PublicKey, digest, sig []byte) error {
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest, sig) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, sig)
+	default:
+		return fmt.Errorf("unsupported %T public key", pub)
+	}
+}
+
+// certIssuer returns the OIDC issuer recorded in a Fulcio certificate.
+func certIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidFulcioIssuerV2):
+			var issuer string
+			if _, err := asn1.Unmarshal(ext.Value, &issuer); err == nil {
+				return issuer
+			}
+		case ext.Id.Equal(oidFulcioIssuer):
+			return string(ext.Value)
+		}
+	}
+
+	return ""
+}
+
+func parseCertificate(content []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("no PEM encoded certificate")
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// verifyCertChain checks cert chains to roots, and that every certificate of the chain is valid at the given time.
+func verifyCertChain(cert *x509.Certificate, roots []byte, at time.Time) error {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(roots) {
+		return errors.New("no certificate in roots")
+	}
+
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:       pool,
+		CurrentTime: at,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+
+	return err
+}
+
+// certIdentities returns the subjects (emails, DNS names and URIs) of a certificate.
+func certIdentities(cert *x509.Certificate) []string {
+	identities := append(slices.Clone(cert.EmailAddresses), cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+
+	return identities
+}
+
+// checkCertIdentity checks the subject and the issuer of the keyless signing certificate.
+func (u *Updater) checkCertIdentity(cert *x509.Certificate) error {
+	identities := certIdentities(cert)
+	if !slices.Contains(identities, u.cosign.Identity) {
+		return fmt.Errorf("certificate identities [%s] don't match %s", strings.Join(identities, ", "), u.cosign.Identity)
+	}
+
+	if issuer := certIssuer(cert); issuer != u.cosign.Issuer {
+		return fmt.Errorf("certificate issuer %q doesn't match %s", issuer, u.cosign.Issuer)
+	}
+
+	return nil
+}
+
+// rekorEntry is a transparency log entry, as returned by `/api/v1/log/entries/{uuid}`.
+type rekorEntry struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+	Verification   struct {
+		SignedEntryTimestamp string `json:"signedEntryTimestamp"`
+	} `json:"verification"`
+}
+
+// hashedRekord is the body of a `hashedrekord` entry, which `cosign sign-blob` logs.
+type hashedRekord struct {
+	Kind string `json:"kind"`
+	Spec struct {
+		Data struct {
+			Hash struct {
+				Algorithm string `json:"algorithm"`
+				Value     string `json:"value"`
+			} `json:"hash"`
+		} `json:"data"`
+		Signature struct {
+			Content   string `json:"content"`
+			PublicKey struct {
+				Content string `json:"content"`
+			} `json:"publicKey"`
+		} `json:"signature"`
+	} `json:"spec"`
+}
+
+// rekorRequest sends a request to the Rekor API at rekorURL and decodes its JSON response into v.
+func (u *Updater) rekorRequest(rekorURL, method, path string, body []byte, v any) error {
+	req, err := http.NewRequestWithContext(u.ctx, method, strings.TrimSuffix(rekorURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build rekor request -> %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query rekor -> %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to query rekor: %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode rekor response -> %w", err)
+	}
+
+	return nil
+}
+
+// checkRekorEntry looks for an entry of the Rekor transparency log at rekorURL binding the signed digest to its signature and to the certificate
+// or public key (PEM encoded) it was signed with. The signed entry timestamp of the entry is checked against rekorKey, and the time it was logged at returned.
+func (u *Updater) checkRekorEntry(rekorURL string, rekorKey []byte, digest string, sig, signer []byte) (time.Time, error) {
+	query, err := json.Marshal(map[string]string{"hash": "sha256:" + digest})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var uuids []string
+	err = u.rekorRequest(rekorURL, http.MethodPost, "/api/v1/index/retrieve", query, &uuids)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	logKey, err := parsePublicKey(rekorKey)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid rekor public key -> %w", err)
+	}
+
+	err = fmt.Errorf("no rekor entry for sha256:%s", digest)
+	for _, uuid := range uuids {
+		var entries map[string]rekorEntry
+		errReq := u.rekorRequest(rekorURL, http.MethodGet, "/api/v1/log/entries/"+url.PathEscape(uuid), nil, &entries)
+		if errReq != nil {
+			return time.Time{}, errReq
+		}
+
+		for _, entry := range entries {
+			integrated, errEntry := checkRekorBinding(entry, logKey, digest, sig, signer)
+			if errEntry == nil {
+				return integrated, nil
+			}
+			err = fmt.Errorf("rekor entry %s -> %w", uuid, errEntry)
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// checkRekorBinding verifies the signed entry timestamp of entry, and that it logs the given digest, signature and signer.
+func checkRekorBinding(entry rekorEntry, rekorKey crypto.PublicKey, digest string, sig, signer []byte) (time.Time, error) {
+	// the timestamp is signed over the canonical JSON (sorted keys, no spaces) of these fields, which is how json.Marshal writes this struct.
+	signed, err := json.Marshal(struct {
+		Body           string `json:"body"`
+		IntegratedTime int64  `json:"integratedTime"`
+		LogID          string `json:"logID"`
+		LogIndex       int64  `json:"logIndex"`
+	}{entry.Body, entry.IntegratedTime, entry.LogID, entry.LogIndex})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	set, err := base64.StdEncoding.DecodeString(entry.Verification.SignedEntryTimestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid signed entry timestamp -> %w", err)
+	}
+	sum := sha256.Sum256(signed)
+	if err := verifyDigestSignature(rekorKey, sum[:], set); err != nil {
+		return time.Time{}, fmt.Errorf("invalid signed entry timestamp -> %w", err)
+	}
+
+	rawBody, err := base64.StdEncoding.DecodeString(entry.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid body -> %w", err)
+	}
+
+	var body hashedRekord
+	if err := json.Unmarshal(rawBody, &body); err != nil {
+		return time.Time{}, fmt.Errorf("invalid body -> %w", err)
+	}
+	if body.Kind != "hashedrekord" {
+		return time.Time{}, fmt.Errorf("unsupported %q entry", body.Kind)
+	}
+	if body.Spec.Data.Hash.Algorithm != "sha256" || !strings.EqualFold(body.Spec.Data.Hash.Value, digest) {
+		return time.Time{}, errors.New("digest mismatch")
+	}
+
+	loggedSig, err := base64.StdEncoding.DecodeString(body.Spec.Signature.Content)
+	if err != nil || !bytes.Equal(loggedSig, sig) {
+		return time.Time{}, errors.New("signature mismatch")
+	}
+
+	loggedSigner, err := base64.StdEncoding.DecodeString(body.Spec.Signature.PublicKey.Content)
+	if err != nil || !samePEM(loggedSigner, signer) {
+		return time.Time{}, errors.New("certificate or public key mismatch")
+	}
+
+	return time.Unix(entry.IntegratedTime, 0), nil
+}
+
+// samePEM tells if a and b hold the same PEM block, whatever their formatting.
+func samePEM(a, b []byte) bool {
+	blockA, _ := pem.Decode(a)
+	blockB, _ := pem.Decode(b)
+
+	return blockA != nil && blockB != nil && bytes.Equal(blockA.Bytes, blockB.Bytes)
+}
+
+func (u *Updater) verifyCosignSignature() error {
+	if u.cosign == nil {
+		return nil
+	}
+
+	err := u.checkCosignSignature()
+	if err != nil {
+		return fmt.Errorf("%w: cosign -> %w", ErrSignatureVerification, err)
+	}
+
+	return nil
+}
+
+func (u *Updater) checkCosignSignature() error {
+	asset := u.getSignatureAsset(".sig")
+	if asset == nil {
+		return fmt.Errorf("no signature asset for %s", u.assetName)
+	}
+
+	var sig []byte
+	err := u.retry(func() (err error) {
+		sig, err = u.fetchSignature(asset)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	sig = decodeCosignAsset(sig)
+
+	var (
+		pub    crypto.PublicKey
+		signer []byte
+		cert   *x509.Certificate
+	)
+	if len(u.cosign.PublicKey) > 0 {
+		signer = u.cosign.PublicKey
+		pub, err = parsePublicKey(signer)
+		if err != nil {
+			return fmt.Errorf("failed to read public key -> %w", err)
+		}
+	} else {
+		certAsset := u.getSignatureAsset(".pem", ".crt")
+		if certAsset == nil {
+			return fmt.Errorf("no certificate asset for %s", u.assetName)
+		}
+
+		err = u.retry(func() (err error) {
+			signer, err = u.fetchSignature(certAsset)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		signer = decodeCosignAsset(signer)
+
+		cert, err = parseCertificate(signer)
+		if err == nil {
+			err = u.checkCertIdentity(cert)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid certificate %s -> %w", certAsset.GetName(), err)
+		}
+		pub = cert.PublicKey
+	}
+
+	sum, err := u.downloadedHash(sha256.New())
+	if err != nil {
+		return err
+	}
+
+	digest, err := hex.DecodeString(sum)
+	if err != nil {
+		return err
+	}
+
+	err = verifyDigestSignature(pub, digest, sig)
+	if err != nil {
+		return fmt.Errorf("%s -> %w", asset.GetName(), err)
+	}
+
+	if u.cosign.RekorURL == "" {
+		return nil
+	}
+
+	var integrated time.Time
+	err = u.retry(func() (err error) {
+		integrated, err = u.checkRekorEntry(u.cosign.RekorURL, u.cosign.RekorPublicKey, sum, sig, signer)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// the short-lived certificate must have been valid when the signature was logged, which is the only trusted time.
+	if cert != nil {
+		err = verifyCertChain(cert, u.cosign.Roots, integrated)
+		if err != nil {
+			return fmt.Errorf("invalid certificate at %s -> %w", integrated.UTC().Format(time.RFC3339), err)
+		}
+	}
+
+	return nil
+}
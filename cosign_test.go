@@ -0,0 +1,227 @@
+package selfupdater
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+const testIdentity = "https://github.com/owner/repo/.github/workflows/release.yml@refs/tags/v1.1.0"
+
+// keylessFixture is a keyless signature of an asset, with the Fulcio and Rekor instances it was signed and logged with.
+type keylessFixture struct {
+	roots     []byte
+	rekorKey  []byte
+	asset     []byte
+	sig       []byte
+	certPEM   []byte
+	key       *ecdsa.PrivateKey
+	signedAt  time.Time
+	rekorSign *ecdsa.PrivateKey
+}
+
+func newKeylessFixture(t *testing.T, signedAt time.Time) *keylessFixture {
+	t.Helper()
+
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fulcio"},
+		NotBefore:             signedAt.Add(-24 * time.Hour),
+		NotAfter:              signedAt.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	issuer, _ := asn1.Marshal("https://token.actions.githubusercontent.com")
+	identity, _ := url.Parse(testIdentity)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leaf := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       signedAt.Add(-time.Minute),
+		NotAfter:        signedAt.Add(10 * time.Minute),
+		URIs:            []*url.URL{identity},
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{{Id: oidFulcioIssuerV2, Value: issuer}},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leaf, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	asset := []byte("new binary")
+	digest := sha256.Sum256(asset)
+	sig, _ := ecdsa.SignASN1(rand.Reader, key, digest[:])
+
+	rekorKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rekorDER, _ := x509.MarshalPKIXPublicKey(&rekorKey.PublicKey)
+
+	return &keylessFixture{
+		roots:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		rekorKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rekorDER}),
+		asset:     asset,
+		sig:       sig,
+		certPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		key:       key,
+		signedAt:  signedAt,
+		rekorSign: rekorKey,
+	}
+}
+
+// rekorServer serves a single entry logging the given signature and certificate for the fixture asset.
+func (f *keylessFixture) rekorServer(t *testing.T, sig []byte) *httptest.Server {
+	t.Helper()
+
+	digest := sha256.Sum256(f.asset)
+	return f.rekorEntryServer(t, digest[:], sig)
+}
+
+// rekorEntryServer serves a single entry logging the given digest, signature and the fixture certificate.
+func (f *keylessFixture) rekorEntryServer(t *testing.T, digest, sig []byte) *httptest.Server {
+	t.Helper()
+
+	var body hashedRekord
+	body.Kind = "hashedrekord"
+	body.Spec.Data.Hash.Algorithm = "sha256"
+	body.Spec.Data.Hash.Value = hex.EncodeToString(digest)
+	body.Spec.Signature.Content = base64.StdEncoding.EncodeToString(sig)
+	body.Spec.Signature.PublicKey.Content = base64.StdEncoding.EncodeToString(f.certPEM)
+	rawBody, _ := json.Marshal(body)
+
+	entry := rekorEntry{Body: base64.StdEncoding.EncodeToString(rawBody), IntegratedTime: f.signedAt.Unix(), LogID: "log", LogIndex: 42}
+	signed, _ := json.Marshal(map[string]any{"body": entry.Body, "integratedTime": entry.IntegratedTime, "logID": entry.LogID, "logIndex": entry.LogIndex})
+	sum := sha256.Sum256(signed)
+	set, _ := ecdsa.SignASN1(rand.Reader, f.rekorSign, sum[:])
+	entry.Verification.SignedEntryTimestamp = base64.StdEncoding.EncodeToString(set)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/index/retrieve":
+			json.NewEncoder(w).Encode([]string{"uuid"})
+		case strings.HasPrefix(r.URL.Path, "/api/v1/log/entries/"):
+			json.NewEncoder(w).Encode(map[string]rekorEntry{"uuid": entry})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func (f *keylessFixture) updater(t *testing.T, opts CosignOptions) *Updater {
+	t.Helper()
+
+	provider := &fakeProvider{}
+	rel := provider.addRelease("v1.1.0", map[string][]byte{
+		"app_linux_amd64":     f.asset,
+		"app_linux_amd64.sig": []byte(base64.StdEncoding.EncodeToString(f.sig)),
+		"app_linux_amd64.pem": []byte(base64.StdEncoding.EncodeToString(f.certPEM)),
+	})
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithCosignVerification(opts))
+	if err := u.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	u.assets = rel.Assets
+	u.assetName = "app_linux_amd64"
+	u.tmpPath = filepath.Join(t.TempDir(), u.assetName)
+	if err := os.WriteFile(u.tmpPath, f.asset, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func (f *keylessFixture) options(rekorURL string) CosignOptions {
+	return CosignOptions{
+		Roots:          f.roots,
+		Identity:       testIdentity,
+		Issuer:         "https://token.actions.githubusercontent.com",
+		RekorURL:       rekorURL,
+		RekorPublicKey: f.rekorKey,
+	}
+}
+
+func TestCosignKeyless(t *testing.T) {
+	f := newKeylessFixture(t, time.Now().Add(-48*time.Hour))
+	rekor := f.rekorServer(t, f.sig)
+	defer rekor.Close()
+
+	// the certificate expired long ago, it is checked at the time the signature was logged.
+	err := f.updater(t, f.options(rekor.URL)).verifyCosignSignature()
+	if err != nil {
+		t.Fatalf("expected a valid signature, got %v", err)
+	}
+}
+
+func TestCosignKeylessUnrelatedEntry(t *testing.T) {
+	f := newKeylessFixture(t, time.Now())
+	other := newKeylessFixture(t, time.Now())
+	// an entry exists for the digest, but for another signature.
+	rekor := f.rekorServer(t, other.sig)
+	defer rekor.Close()
+
+	err := f.updater(t, f.options(rekor.URL)).verifyCosignSignature()
+	if !errors.Is(err, ErrSignatureVerification) {
+		t.Fatalf("expected ErrSignatureVerification, got %v", err)
+	}
+}
+
+func TestCosignKeylessWrongIdentity(t *testing.T) {
+	f := newKeylessFixture(t, time.Now())
+	rekor := f.rekorServer(t, f.sig)
+	defer rekor.Close()
+
+	opts := f.options(rekor.URL)
+	opts.Identity = "https://github.com/attacker/repo/.github/workflows/release.yml@refs/tags/v1.1.0"
+	err := f.updater(t, opts).verifyCosignSignature()
+	if !errors.Is(err, ErrSignatureVerification) {
+		t.Fatalf("expected ErrSignatureVerification, got %v", err)
+	}
+}
+
+func TestCosignKeylessRequiresIdentity(t *testing.T) {
+	f := newKeylessFixture(t, time.Now())
+
+	for name, opts := range map[string]CosignOptions{
+		"identity":   {Roots: f.roots, Issuer: "issuer", RekorURL: "https://rekor.sigstore.dev", RekorPublicKey: f.rekorKey},
+		"issuer":     {Roots: f.roots, Identity: testIdentity, RekorURL: "https://rekor.sigstore.dev", RekorPublicKey: f.rekorKey},
+		"rekor":      {Roots: f.roots, Identity: testIdentity, Issuer: "issuer"},
+		"rekor key":  {Roots: f.roots, Identity: testIdentity, Issuer: "issuer", RekorURL: "https://rekor.sigstore.dev"},
+		"no signer":  {},
+		"key, rekor": {PublicKey: f.rekorKey, RekorURL: "https://rekor.sigstore.dev"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			u := New("owner", "repo", semver.MustParse("1.0.0"), WithCosignVerification(opts))
+			if !errors.Is(u.Err(), ErrInvalidOption) {
+				t.Fatalf("expected ErrInvalidOption, got %v", u.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/gabstv/go-bsdiff/pkg/bspatch"
+	"github.com/google/go-github/v59/github"
+)
+
+var errNoPatch = errors.New("no patch asset for the current version")
+
+// WithDeltaUpdates makes the [Updater] look for a bsdiff patch from the current version in the release
+// (named `<asset name>-v<current version>.bsdiff`, like `myapp_linux-amd64-v1.2.3.bsdiff`) and apply it to the current executable
+// instead of downloading the whole asset. The full asset is downloaded if there is no patch, if the release publishes no checksum
+// of the asset (in a checksum asset or the manifest), or if the patched binary doesn't match the size and checksum of the asset.
+// Archived assets are never patched.
+func WithDeltaUpdates(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.deltaUpdates = enabled
+	}
+}
+
+func (u *Updater) getPatchAsset() *github.ReleaseAsset {
+	names := []string{
+		fmt.Sprintf("%s-v%s.bsdiff", u.assetName, u.Current),
+		fmt.Sprintf("%s-%s.bsdiff", u.assetName, u.Current),
+	}
+
+	index := slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
+		return slices.Contains(names, ra.GetName())
+	})
+
+	if index == -1 {
+		return nil
+	}
+
+	return u.assets[index]
+}
+
+// downloadPatched builds the new binary at u.tmpPath by applying the release patch to the current executable.
+// It returns errNoPatch if the release has no patch for the current version.
+func (u *Updater) downloadPatched() error {
+	if archiveKindOf(u.assetName) != archiveNone {
+		return errNoPatch
+	}
+
+	asset := u.getPatchAsset()
+	if asset == nil {
+		return errNoPatch
+	}
+
+	// a patched binary can't be trusted without a checksum to compare it with.
+	if !u.hasManifestChecksum() && u.getChecksumAsset() == nil {
+		return fmt.Errorf("%w: no checksum in release to verify patch %s", ErrChecksumNotFound, asset.GetName())
+	}
+
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return err
+	}
+
+	old, err := u.fsys.Open(exePath)
+	if err != nil {
+		return fmt.Errorf("failed to open current executable -> %w", err)
+	}
+	defer old.Close()
+
+	patch, err := u.openAsset(asset.GetID())
+	if err != nil {
+		return err
+	}
+	defer patch.Close()
+
+	u.tmpPath = filepath.Join(u.tmpDir, u.assetName)
+	u.logger.Info("patching current executable", "patch", asset.GetName(), "path", u.tmpPath)
+
+	f, err := u.fsys.Create(u.tmpPath, false, 0666)
+	if err != nil {
+		return fmt.Errorf("%w: failed to create temp patched binary -> %w", ErrDownloadFailed, err)
+	}
+
+	err = bspatch.Reader(old, f, &contextReader{ctx: u.ctx, reader: patch})
+	f.Close()
+	if err == nil {
+		err = u.checkPatched()
+	}
+	if err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return fmt.Errorf("failed to apply patch %s -> %w", asset.GetName(), err)
+	}
+
+	return nil
+}
+
+// checkPatched makes sure the patched binary is the release asset, against the checksum of the manifest or else the checksum asset,
+// which must have an entry for the asset.
+func (u *Updater) checkPatched() error {
+	info, err := u.fsys.Stat(u.tmpPath)
+	if err != nil {
+		return err
+	}
+
+	if u.assetSize > 0 && info.Size() != u.assetSize {
+		return fmt.Errorf("%w: patched binary is %d bytes, expected %d", ErrChecksumMismatch, info.Size(), u.assetSize)
+	}
+
+	if u.hasManifestChecksum() {
+		return u.verifyManifestChecksum()
+	}
+	return u.checkChecksum(true)
+}
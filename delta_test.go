@@ -0,0 +1,80 @@
+package selfupdater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blang/semver"
+	"github.com/gabstv/go-bsdiff/pkg/bsdiff"
+)
+
+func deltaUpdater(t *testing.T, assets map[string][]byte) (*Updater, *fakeProvider, *memFS) {
+	t.Helper()
+
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", assets)
+
+	fsys := newMemFS()
+	fsys.files["app/repo"] = &fstest.MapFile{Data: elfBinary("v1"), Mode: 0755}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithFileSystem(fsys), WithTargetPath("/app/repo"),
+		WithPlatform("linux", "amd64"), WithLaunchVerification(false), WithDeltaUpdates(true))
+
+	return u, provider, fsys
+}
+
+func bsdiffPatch(t *testing.T, from, to []byte) []byte {
+	t.Helper()
+
+	patch, err := bsdiff.Bytes(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return patch
+}
+
+func TestDeltaUpdateWithChecksum(t *testing.T) {
+	newBinary := elfBinary("v2")
+	sum := sha256.Sum256(newBinary)
+
+	u, provider, fsys := deltaUpdater(t, map[string][]byte{
+		"repo_linux_amd64":               newBinary,
+		"repo_linux_amd64-v1.0.0.bsdiff": bsdiffPatch(t, elfBinary("v1"), newBinary),
+		"checksums.txt":                  []byte(hex.EncodeToString(sum[:]) + "  repo_linux_amd64\n"),
+	})
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/repo"); got != string(newBinary) {
+		t.Errorf("installed binary is %q", got)
+	}
+	if slices.Contains(provider.downloads, "repo_linux_amd64") {
+		t.Errorf("full asset downloaded despite the patch: %v", provider.downloads)
+	}
+}
+
+func TestDeltaUpdateWithoutChecksum(t *testing.T) {
+	newBinary := elfBinary("v2")
+
+	// the patch doesn't produce the release asset, which can't be noticed without a checksum.
+	u, provider, fsys := deltaUpdater(t, map[string][]byte{
+		"repo_linux_amd64":               newBinary,
+		"repo_linux_amd64-v1.0.0.bsdiff": bsdiffPatch(t, elfBinary("v1"), elfBinary("v3")),
+	})
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/repo"); got != string(newBinary) {
+		t.Errorf("installed binary is %q", got)
+	}
+	if slices.Contains(provider.downloads, "repo_linux_amd64-v1.0.0.bsdiff") {
+		t.Errorf("patch downloaded without a checksum to verify it: %v", provider.downloads)
+	}
+}
@@ -0,0 +1,42 @@
+package selfupdater
+
+import (
+	"io"
+	"slices"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// WithDirectDownload makes the [Updater] download the release assets from their public download URL (`browser_download_url`), served by the CDN,
+// rather than through the API, so that large downloads don't consume the API rate limit. Assets of private repositories can't be downloaded this way:
+// when the direct download fails (like with a 404 status for a private repository), the asset is downloaded through the API as usual.
+// Resumed and concurrent downloads (see [WithConcurrentDownload]) still go through the API.
+func WithDirectDownload(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.directDownload = enabled
+	}
+}
+
+// openDirect streams the release asset with the given id from its public download URL, see [WithDirectDownload].
+// It returns false if the asset must be downloaded through the provider instead.
+func (u *Updater) openDirect(id int64) (io.ReadCloser, bool) {
+	if !u.directDownload {
+		return nil, false
+	}
+
+	index := slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
+		return ra.GetID() == id
+	})
+	if index == -1 || u.assets[index].GetBrowserDownloadURL() == "" {
+		return nil, false
+	}
+
+	rawURL := u.assets[index].GetBrowserDownloadURL()
+	reader, err := u.downloadURL(rawURL)
+	if err != nil {
+		u.logger.Debug("direct download failed, downloading through the API", "url", rawURL, "error", err)
+		return nil, false
+	}
+
+	return reader, true
+}
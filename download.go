@@ -0,0 +1,72 @@
+package selfupdater
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// DownloadTo fetches the asset of the latest release matching the platform and saves it as is (archives are not extracted) to destPath,
+// after the same checksum and signature verification as [Updater.Update]. If destPath is a directory, the asset keeps its name inside it.
+// The current executable is left untouched: nothing is swapped nor launched. It returns the path of the saved asset.
+func (u *Updater) DownloadTo(destPath string) (string, error) {
+	if u.err != nil {
+		return "", u.err
+	}
+
+	defer u.withDeadline()()
+
+	rel, latest, err := u.latestRelease()
+	if err != nil {
+		return "", err
+	}
+
+	u.assets = rel.Assets
+	u.target = latest
+
+	asset, err := u.getAsset()
+	if err != nil {
+		return "", err
+	}
+
+	u.setAsset(asset)
+
+	err = u.checkAssetSize()
+	if err != nil {
+		return "", err
+	}
+
+	if info, err := u.fsys.Stat(destPath); err == nil && info.IsDir() {
+		destPath = filepath.Join(destPath, u.assetName)
+	}
+
+	if !u.inMemory {
+		err = u.prepareTempDir()
+		if err != nil {
+			return "", err
+		}
+		defer u.fsys.Remove(u.tmpDir)
+	}
+
+	err = u.retry(u.fetchAsset)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.verifyAsset()
+	if err != nil {
+		return "", err
+	}
+
+	if u.memAsset != nil {
+		err = u.writeFile(destPath, u.memAsset, 0644)
+		u.memAsset = nil
+	} else {
+		err = moveFile(u.fsys, u.tmpPath, destPath)
+	}
+	if err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return "", fmt.Errorf("%w: failed to save %s to %s -> %w", ErrDownloadFailed, u.assetName, destPath, err)
+	}
+
+	return destPath, nil
+}
@@ -0,0 +1,52 @@
+package selfupdater
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// WithEnterpriseURL makes the [Updater] work against a GitHub Enterprise Server instance instead of github.com.
+// uploadURL can be left empty, baseURL is used in that case. The `/api/v3/` suffix is added if missing.
+// A malformed URL makes every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithEnterpriseURL(baseURL, uploadURL string) UpdaterOpts {
+	return func(u *Updater) {
+		u.enterpriseURL = baseURL
+		u.uploadURL = uploadURL
+	}
+}
+
+func validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: malformed enterprise url %q -> %w", ErrInvalidOption, rawURL, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: enterprise url %q must be an absolute http(s) url", ErrInvalidOption, rawURL)
+	}
+
+	return nil
+}
+
+// withEnterpriseURLs returns a copy of client pointing at the enterprise instance.
+// The given client is returned along with the error if the URLs are invalid, so that the [Updater] is always usable.
+func withEnterpriseURLs(client *github.Client, baseURL, uploadURL string) (*github.Client, error) {
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+
+	for _, rawURL := range []string{baseURL, uploadURL} {
+		if err := validateURL(rawURL); err != nil {
+			return client, err
+		}
+	}
+
+	enterprise, err := client.WithEnterpriseURLs(baseURL, uploadURL)
+	if err != nil {
+		return client, fmt.Errorf("%w: malformed enterprise url -> %w", ErrInvalidOption, err)
+	}
+
+	return enterprise, nil
+}
@@ -0,0 +1,53 @@
+package selfupdater
+
+import "errors"
+
+// Errors returned by the [Updater]. They are wrapped with some context so use [errors.Is] to check them.
+var (
+	// ErrAssetNotFound is returned when no asset of the release matches the current platform.
+	ErrAssetNotFound = errors.New("release asset not found")
+	// ErrNoAssetsYet is returned when the release has no asset at all, usually because they are still being uploaded: try again later.
+	ErrNoAssetsYet = errors.New("release has no assets yet")
+	// ErrAssetTooLarge is returned when the release asset is larger than the limit set with [WithMaxDownloadSize].
+	ErrAssetTooLarge = errors.New("release asset too large")
+	// ErrDownloadFailed is returned when a release asset can't be downloaded.
+	ErrDownloadFailed = errors.New("download failed")
+	// ErrIncompleteDownload is returned when the downloaded release asset doesn't have the size reported by github.
+	ErrIncompleteDownload = errors.New("incomplete download")
+	// ErrInstallFailed is returned when the downloaded binary can't be installed in place of the current one.
+	ErrInstallFailed = errors.New("install failed")
+	// ErrRateLimited is returned when github rate limits are exceeded, see [RateLimitError] to know when they reset.
+	ErrRateLimited = errors.New("github rate limit exceeded")
+	// ErrRollbackFailed is returned when the old binary can't be restored after an unsuccessful install.
+	ErrRollbackFailed = errors.New("rollback failed")
+	// ErrReleaseNotFound is returned when the requested release doesn't exist.
+	ErrReleaseNotFound = errors.New("release not found")
+	// ErrInvalidOption is returned when an option given to [New] is invalid.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrBackupNotFound is returned when there is no backup of the requested version to rollback to.
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrChecksumMismatch is returned when the downloaded release asset doesn't match the checksum published in the release.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrChecksumNotFound is returned when checksum verification is required but the release doesn't provide a checksum for the downloaded asset.
+	ErrChecksumNotFound = errors.New("checksum not found")
+	// ErrSignatureVerification is returned when the signature of the downloaded release asset is missing or invalid.
+	ErrSignatureVerification = errors.New("signature verification failed")
+	// ErrProvenanceVerification is returned when the SLSA provenance of the downloaded release asset is missing or doesn't match it, see [WithSLSAVerification].
+	ErrProvenanceVerification = errors.New("provenance verification failed")
+	// ErrBrokenRollback is returned when the binary restored by a rollback fails its test launch too, see [WithRollbackVerification].
+	ErrBrokenRollback = errors.New("restored binary is broken")
+	// ErrInvalidBinary is returned when the binary to install is not an executable for the platform, like an HTML error page.
+	ErrInvalidBinary = errors.New("invalid binary")
+	// ErrUnknownVersion is returned when the current version can't be read from the build info, see [NewFromBuildInfo].
+	ErrUnknownVersion = errors.New("current version unknown")
+	// ErrVersionMismatch is returned when the installed binary doesn't report the version of the release, see [WithVersionCheckCommand].
+	ErrVersionMismatch = errors.New("installed version mismatch")
+	// ErrUpdateInProgress is returned when another update of the same executable is running, in this process or another one.
+	ErrUpdateInProgress = errors.New("update already in progress")
+	// ErrServiceRestart is returned when the service can't be restarted once the new binary is installed, see [WithServiceManager].
+	ErrServiceRestart = errors.New("service restart failed")
+	// ErrNotWritable is returned when the binary to replace is in a directory the current user can't write to.
+	ErrNotWritable = errors.New("binary not writable")
+	// ErrAborted is returned when a hook given with [WithHooks] aborts the update, or when it is declined (see [WithConfirm]).
+	ErrAborted = errors.New("update aborted")
+)
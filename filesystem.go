@@ -0,0 +1,123 @@
+package selfupdater
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem is the file operations made by the [Updater] on the download directory, the binary it replaces and the files next to it, see [WithFileSystem].
+type FileSystem interface {
+	Open(name string) (fs.File, error)
+	// Create opens name for writing, creating it with perm (before umask) if needed. It is truncated unless appendTo is set.
+	// A download in parts (see [WithConcurrentDownload]) requires the returned file to implement [io.WriterAt].
+	Create(name string, appendTo bool, perm fs.FileMode) (io.WriteCloser, error)
+	Rename(oldpath, newpath string) error
+	Remove(name string) error
+	Chmod(name string, mode fs.FileMode) error
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// TempDir creates a new directory to download the release asset to, it is removed with Remove once empty.
+	TempDir() (string, error)
+}
+
+// WithFileSystem makes the [Updater] download, verify, extract, install and roll back releases through fsys rather than the os package, e.g. for a sandbox
+// mediating file access through a virtual file system, or to test the install logic without touching the disk. The download directory is the one returned
+// by [FileSystem.TempDir] ([WithTempDir] doesn't apply), and symlinks to the binary are not resolved. The lock preventing concurrent updates only applies across processes if fsys creates [os.File]s,
+// and the ownership of the replaced binary is kept with the default file system only. Launching the new binary, code signing on macOS (see [WithCodesignIdentity])
+// and the removal of the old binary on reboot on windows operate on the host paths.
+func WithFileSystem(fsys FileSystem) UpdaterOpts {
+	return func(u *Updater) {
+		if fsys != nil {
+			u.fsys = fsys
+		}
+	}
+}
+
+// osFileSystem is the default [FileSystem], backed by the os package.
+type osFileSystem struct{}
+
+func (osFileSystem) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFileSystem) Create(name string, appendTo bool, perm fs.FileMode) (io.WriteCloser, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendTo {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	return os.OpenFile(name, flags, perm)
+}
+
+func (osFileSystem) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+func (osFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFileSystem) Chmod(name string, mode fs.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+func (osFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (osFileSystem) TempDir() (string, error) {
+	return os.MkdirTemp("", "selfupdater-*")
+}
+
+// customFileSystem tells if a [FileSystem] was given with [WithFileSystem].
+func (u *Updater) customFileSystem() bool {
+	_, ok := u.fsys.(osFileSystem)
+	return !ok
+}
+
+// writeFile writes data to name, like os.WriteFile.
+func (u *Updater) writeFile(name string, data []byte, perm fs.FileMode) error {
+	f, err := u.fsys.Create(name, false, perm)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	return errors.Join(err, f.Close())
+}
+
+// removeAll removes path and its content, if any.
+func removeAll(fsys FileSystem, path string) error {
+	if _, ok := fsys.(osFileSystem); ok {
+		return os.RemoveAll(path)
+	}
+
+	info, err := fsys.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		entries, err := fsys.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := removeAll(fsys, filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	return fsys.Remove(path)
+}
@@ -0,0 +1,373 @@
+package selfupdater
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// memFS is an in-memory [FileSystem]: directories exist as long as they hold a file, or were created with TempDir.
+type memFS struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+	temps int
+}
+
+func newMemFS() *memFS {
+	return &memFS{files: fstest.MapFS{}}
+}
+
+func memKey(name string) string {
+	return strings.TrimPrefix(filepath.ToSlash(name), "/")
+}
+
+func (m *memFS) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// the file is opened on a snapshot, so that it can be written meanwhile.
+	f, ok := m.files[memKey(name)]
+	if !ok || f.Mode.IsDir() {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return fstest.MapFS{"f": {Data: slices.Clone(f.Data), Mode: f.Mode}}.Open("f")
+}
+
+type memWriter struct {
+	m      *memFS
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *memWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (w *memWriter) Close() error {
+	w.m.mu.Lock()
+	defer w.m.mu.Unlock()
+
+	// like an *os.File, the file is only written on the first close.
+	f, ok := w.m.files[w.name]
+	if w.closed {
+		return fs.ErrClosed
+	}
+	w.closed = true
+	if ok {
+		f.Data = slices.Clone(w.buf.Bytes())
+	}
+	return nil
+}
+
+func (m *memFS) Create(name string, appendTo bool, perm fs.FileMode) (io.WriteCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memKey(name)
+	if _, err := m.dirLocked(path.Dir(key)); err != nil {
+		return nil, err
+	}
+
+	w := &memWriter{m: m, name: key}
+	f, ok := m.files[key]
+	if !ok {
+		f = &fstest.MapFile{Mode: perm, ModTime: time.Now()}
+		m.files[key] = f
+	}
+	if appendTo {
+		w.buf.Write(f.Data)
+	}
+	return w, nil
+}
+
+// dirLocked makes sure dir exists: the root or a directory holding a file or created by TempDir.
+func (m *memFS) dirLocked(dir string) (bool, error) {
+	if dir == "." {
+		return true, nil
+	}
+	if f, ok := m.files[dir]; ok && f.Mode.IsDir() {
+		return true, nil
+	}
+	for key := range m.files {
+		if strings.HasPrefix(key, dir+"/") {
+			return true, nil
+		}
+	}
+	return false, &fs.PathError{Op: "open", Path: dir, Err: fs.ErrNotExist}
+}
+
+func (m *memFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[memKey(oldpath)]
+	if !ok {
+		return &fs.PathError{Op: "rename", Path: oldpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, memKey(oldpath))
+	m.files[memKey(newpath)] = f
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memKey(name)
+	f, ok := m.files[key]
+	if !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	if f.Mode.IsDir() {
+		for other := range m.files {
+			if strings.HasPrefix(other, key+"/") {
+				return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+			}
+		}
+	}
+	delete(m.files, key)
+	return nil
+}
+
+func (m *memFS) Chmod(name string, mode fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[memKey(name)]
+	if !ok {
+		return &fs.PathError{Op: "chmod", Path: name, Err: fs.ErrNotExist}
+	}
+	f.Mode = f.Mode&fs.ModeType | mode.Perm()
+	return nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return fs.Stat(m.files, memKey(name))
+}
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memKey(name)
+	if key == "" {
+		key = "."
+	}
+	return fs.ReadDir(m.files, key)
+}
+
+func (m *memFS) TempDir() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.temps++
+	dir := "tmp/update-" + strings.Repeat("x", m.temps)
+	m.files[dir] = &fstest.MapFile{Mode: fs.ModeDir | 0755}
+	return "/" + dir, nil
+}
+
+func (m *memFS) content(t *testing.T, name string) string {
+	t.Helper()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.files[memKey(name)]
+	if !ok {
+		t.Fatalf("%s doesn't exist", name)
+	}
+	return string(f.Data)
+}
+
+// elfBinary is the content of a fake linux executable.
+func elfBinary(content string) []byte {
+	return []byte("\x7fELF" + content)
+}
+
+func TestWithFileSystemInstall(t *testing.T) {
+	fsys := newMemFS()
+	fsys.files["app/bin/app"] = &fstest.MapFile{Data: elfBinary("v1"), Mode: 0755}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"),
+		WithFileSystem(fsys), WithTargetPath("/app/bin/app"), WithPlatform("linux", "amd64"), WithLaunchVerification(false))
+
+	err := u.InstallFromReader(bytes.NewReader(elfBinary("v2")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/bin/app"); got != string(elfBinary("v2")) {
+		t.Errorf("installed binary is %q", got)
+	}
+	if got := fsys.content(t, "/app/bin/app-old"); got != string(elfBinary("v1")) {
+		t.Errorf("old binary is %q", got)
+	}
+	if info, _ := fsys.Stat("/app/bin/app"); info.Mode().Perm() != 0755 {
+		t.Errorf("installed binary mode is %s", info.Mode())
+	}
+}
+
+func TestWithFileSystemDownload(t *testing.T) {
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64.tar.gz": tarGz(t, "repo", elfBinary("v2"))})
+
+	fsys := newMemFS()
+	fsys.files["app/bin/app"] = &fstest.MapFile{Data: elfBinary("v1"), Mode: 0755}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider),
+		WithFileSystem(fsys), WithTargetPath("/app/bin/app"), WithPlatform("linux", "amd64"), WithLaunchVerification(false))
+
+	err := u.Update()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/bin/app"); got != string(elfBinary("v2")) {
+		t.Errorf("installed binary is %q", got)
+	}
+	if !u.Installed.Equals(semver.MustParse("1.1.0")) {
+		t.Errorf("installed version is %s", u.Installed)
+	}
+}
+
+// crashFS is a [memFS] on which every change fails after the first n ones, as if the process crashed by then.
+type crashFS struct {
+	*memFS
+	n       int
+	changes int
+	created []string
+}
+
+var errCrashed = errors.New("crashed")
+
+func (c *crashFS) change() error {
+	if c.changes >= c.n {
+		return errCrashed
+	}
+	c.changes++
+	return nil
+}
+
+func (c *crashFS) Create(name string, appendTo bool, perm fs.FileMode) (io.WriteCloser, error) {
+	if err := c.change(); err != nil {
+		return nil, err
+	}
+	c.created = append(c.created, memKey(name))
+	return c.memFS.Create(name, appendTo, perm)
+}
+
+func (c *crashFS) Rename(oldpath, newpath string) error {
+	if err := c.change(); err != nil {
+		return err
+	}
+	return c.memFS.Rename(oldpath, newpath)
+}
+
+func (c *crashFS) Remove(name string) error {
+	if err := c.change(); err != nil {
+		return err
+	}
+	return c.memFS.Remove(name)
+}
+
+func (c *crashFS) Chmod(name string, mode fs.FileMode) error {
+	if err := c.change(); err != nil {
+		return err
+	}
+	return c.memFS.Chmod(name, mode)
+}
+
+func TestInstallCrashSafety(t *testing.T) {
+	oldBinary, newBinary := string(elfBinary("v1")), string(elfBinary("v2"))
+
+	for n := 0; ; n++ {
+		fsys := &crashFS{memFS: newMemFS(), n: n}
+		fsys.files["app/repo"] = &fstest.MapFile{Data: []byte(oldBinary), Mode: 0755}
+
+		u := New("owner", "repo", semver.MustParse("1.0.0"),
+			WithFileSystem(fsys), WithTargetPath("/app/repo"), WithPlatform("linux", "amd64"), WithLaunchVerification(false))
+		err := u.InstallFromReader(strings.NewReader(newBinary), 0)
+
+		// the executable is replaced by a rename, never written in place.
+		if slices.Contains(fsys.created, "app/repo") {
+			t.Fatalf("the executable is written in place: %v", fsys.created)
+		}
+
+		// whenever the crash happens, the executable is either the old binary or the new one, never missing nor partially written.
+		got := fsys.content(t, "/app/repo")
+		if got != oldBinary && got != newBinary {
+			t.Fatalf("crash after %d changes left the executable %q", n, got)
+		}
+		if info, _ := fsys.Stat("/app/repo"); info.Mode().Perm()&0111 == 0 {
+			t.Fatalf("crash after %d changes left the executable with mode %s", n, info.Mode())
+		}
+
+		if err == nil {
+			if got != newBinary {
+				t.Fatalf("install succeeded after %d changes with executable %q", n, got)
+			}
+			break
+		}
+		if !errors.Is(err, errCrashed) {
+			t.Fatalf("crash after %d changes returned %v", n, err)
+		}
+	}
+}
+
+func firstInstallUpdater(t *testing.T, opts ...UpdaterOpts) (*Updater, *memFS) {
+	t.Helper()
+
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": elfBinary("v2")})
+
+	fsys := newMemFS()
+	fsys.files["opt/bin"] = &fstest.MapFile{Mode: fs.ModeDir | 0755}
+
+	opts = append([]UpdaterOpts{WithProvider(provider), WithFileSystem(fsys), WithTargetPath("/opt/bin/repo"), WithPlatform("linux", "amd64")}, opts...)
+	return New("owner", "repo", semver.MustParse("0.0.0"), opts...), fsys
+}
+
+func TestInstallOnlyFirstInstall(t *testing.T) {
+	// the version command can't run on this file system, it must not be run on a first install.
+	u, fsys := firstInstallUpdater(t, WithVersionCheckCommand([]string{"--version"}, semver.ParseTolerant))
+
+	if err := u.InstallOnly(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/opt/bin/repo"); got != string(elfBinary("v2")) {
+		t.Errorf("installed binary is %q", got)
+	}
+	if _, err := fsys.Stat("/opt/bin/repo-old"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("first install archived an old binary (%v)", err)
+	}
+}
+
+func TestInstallOnlyFirstInstallHookFailure(t *testing.T) {
+	errHook := errors.New("hook failed")
+	u, fsys := firstInstallUpdater(t, WithHooks(Hooks{AfterSwap: func(UpdatePlan) error { return errHook }}))
+
+	err := u.InstallOnly()
+	if !errors.Is(err, ErrInstallFailed) || !errors.Is(err, errHook) || errors.Is(err, ErrRollbackFailed) {
+		t.Fatalf("failing hook on first install returned %v", err)
+	}
+
+	if _, err := fsys.Stat("/opt/bin/repo"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("new binary not removed after the hook failure (%v)", err)
+	}
+}
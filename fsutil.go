@@ -0,0 +1,74 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// moveFile renames src to dst, falling back to a copy followed by the removal of src when the rename fails,
+// typically with EXDEV ("invalid cross-device link") when they are not on the same filesystem.
+func moveFile(fsys FileSystem, src, dst string) error {
+	errRen := fsys.Rename(src, dst)
+	if errRen == nil || errors.Is(errRen, fs.ErrNotExist) {
+		return errRen
+	}
+
+	err := copyFile(fsys, src, dst)
+	if err != nil {
+		if !errors.Is(errRen, syscall.EXDEV) {
+			return errRen
+		}
+		return fmt.Errorf("failed to copy %s to %s after failed rename (%w) -> %w", src, dst, errRen, err)
+	}
+
+	return fsys.Remove(src)
+}
+
+// linkOrCopy makes dst a hard link to src, replacing it if it exists, or a copy of src when it can't be linked (e.g. across filesystems).
+// Only the default file system links files.
+func linkOrCopy(fsys FileSystem, src, dst string) error {
+	err := fsys.Remove(dst)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if _, ok := fsys.(osFileSystem); ok && os.Link(src, dst) == nil {
+		return nil
+	}
+
+	return copyFile(fsys, src, dst)
+}
+
+func copyFile(fsys FileSystem, src, dst string) error {
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := fsys.Create(dst, false, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	err = errors.Join(err, out.Close())
+	if err == nil {
+		// the mode given on creation is altered by the umask.
+		err = fsys.Chmod(dst, info.Mode().Perm())
+	}
+	if err != nil {
+		fsys.Remove(dst)
+	}
+
+	return err
+}
@@ -0,0 +1,75 @@
+package selfupdater
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blang/semver"
+)
+
+// exdevFS is a [memFS] whose top-level directories are distinct devices, files can't be renamed from one to another.
+type exdevFS struct {
+	*memFS
+	renames int
+}
+
+func device(name string) string {
+	dev, _, _ := strings.Cut(memKey(name), "/")
+	return dev
+}
+
+func (e *exdevFS) Rename(oldpath, newpath string) error {
+	if device(oldpath) != device(newpath) {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EXDEV}
+	}
+	e.renames++
+	return e.memFS.Rename(oldpath, newpath)
+}
+
+func TestMoveFileAcrossDevices(t *testing.T) {
+	fsys := &exdevFS{memFS: newMemFS()}
+	fsys.files["tmp/app"] = &fstest.MapFile{Data: []byte("new"), Mode: 0750}
+	fsys.files["bin/keep"] = &fstest.MapFile{}
+
+	if err := moveFile(fsys, "/tmp/app", "/bin/app"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/bin/app"); got != "new" {
+		t.Errorf("moved file is %q", got)
+	}
+	if info, err := fsys.Stat("/bin/app"); err != nil || info.Mode().Perm() != 0750 {
+		t.Errorf("moved file mode is not kept (%v)", err)
+	}
+	if _, err := fsys.Stat("/tmp/app"); !os.IsNotExist(err) {
+		t.Errorf("source file still exists (%v)", err)
+	}
+}
+
+func TestInstallAcrossDevices(t *testing.T) {
+	fsys := &exdevFS{memFS: newMemFS()}
+	fsys.files["app/repo"] = &fstest.MapFile{Data: elfBinary("v1"), Mode: 0755}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"),
+		WithFileSystem(fsys), WithTargetPath("/app/repo"), WithPlatform("linux", "amd64"), WithLaunchVerification(false))
+
+	// the download directory is on another device than the executable.
+	err := u.InstallFromReader(bytes.NewReader(elfBinary("v2")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/repo"); got != string(elfBinary("v2")) {
+		t.Errorf("installed binary is %q", got)
+	}
+	if got := fsys.content(t, "/app/repo-old"); got != string(elfBinary("v1")) {
+		t.Errorf("old binary is %q", got)
+	}
+	if fsys.renames == 0 {
+		t.Error("the new binary is not installed with a rename once staged next to the old one")
+	}
+}
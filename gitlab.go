@@ -0,0 +1,260 @@
+package selfupdater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+var errGitLabNotFound = errors.New("gitlab resource not found")
+
+// GitLabProvider is a [ReleaseProvider] fetching releases from a GitLab project, see [WithProvider].
+// Release assets are the links of the release, their size is unknown.
+type GitLabProvider struct {
+	// Project is the full path of the project, like `group/subgroup/project`.
+	Project string
+	// BaseURL is the URL of the GitLab instance, https://gitlab.com by default.
+	BaseURL string
+	// Token is an optional access token, required for private projects.
+	Token string
+	// Client is the http client used for every call, http.DefaultClient by default.
+	Client *http.Client
+
+	mu        sync.Mutex
+	assetURLs map[int64]string
+}
+
+type gitlabRelease struct {
+	TagName         string    `json:"tag_name"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	ReleasedAt      time.Time `json:"released_at"`
+	UpcomingRelease bool      `json:"upcoming_release"`
+	Links           struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+	Assets struct {
+		Links []struct {
+			ID             int64  `json:"id"`
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+func (g *GitLabProvider) baseURL() string {
+	if g.BaseURL == "" {
+		return defaultGitLabURL
+	}
+	return strings.TrimSuffix(g.BaseURL, "/")
+}
+
+func (g *GitLabProvider) client() *http.Client {
+	if g.Client == nil {
+		return http.DefaultClient
+	}
+	return g.Client
+}
+
+// get performs an authenticated GET on a GitLab URL, the token is never sent to another host.
+func (g *GitLabProvider) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if base, err := url.Parse(g.baseURL()); err == nil && g.Token != "" && req.URL.Host == base.Host {
+		req.Header.Set("PRIVATE-TOKEN", g.Token)
+	}
+
+	resp, err := g.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", errGitLabNotFound, rawURL)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, gitlabError(resp)
+	}
+
+	return resp, nil
+}
+
+// gitlabError describes a non 2xx response with the go-github error types, so that server errors are retried (see [WithRetry])
+// and rate limits waited for (see [WithRateLimitWait]) like with github.
+func gitlabError(resp *http.Response) error {
+	message := "gitlab responded " + resp.Status
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return &github.ErrorResponse{Response: resp, Message: message}
+	}
+
+	rateErr := &github.AbuseRateLimitError{Response: resp, Message: message}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter := time.Duration(seconds) * time.Second
+		rateErr.RetryAfter = &retryAfter
+	}
+
+	return rateErr
+}
+
+func (g *GitLabProvider) apiURL(endpoint string, query url.Values) string {
+	u := fmt.Sprintf("%s/api/v4/projects/%s/releases%s", g.baseURL(), url.PathEscape(g.Project), endpoint)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (g *GitLabProvider) decode(resp *http.Response, v any) error {
+	defer resp.Body.Close()
+
+	err := json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("failed to decode gitlab response -> %w", err)
+	}
+	return nil
+}
+
+// toRelease converts a GitLab release and remembers the URLs of its assets for [GitLabProvider.DownloadAsset].
+func (g *GitLabProvider) toRelease(gr gitlabRelease) *github.RepositoryRelease {
+	rel := &github.RepositoryRelease{
+		TagName:     github.String(gr.TagName),
+		Name:        github.String(gr.Name),
+		Body:        github.String(gr.Description),
+		HTMLURL:     github.String(gr.Links.Self),
+		PublishedAt: &github.Timestamp{Time: gr.ReleasedAt},
+		Prerelease:  github.Bool(gr.UpcomingRelease),
+	}
+
+	for _, link := range gr.Assets.Links {
+		downloadURL := link.DirectAssetURL
+		if downloadURL == "" {
+			downloadURL = link.URL
+		}
+
+		rel.Assets = append(rel.Assets, &github.ReleaseAsset{
+			ID:                 github.Int64(link.ID),
+			Name:               github.String(link.Name),
+			BrowserDownloadURL: github.String(downloadURL),
+		})
+	}
+	g.recordRelease(rel)
+
+	return rel
+}
+
+// recordRelease remembers the download URLs of the assets of rel, which may have been returned by another provider of the same project
+// through the cache (see [WithCacheTTL]).
+func (g *GitLabProvider) recordRelease(rel *github.RepositoryRelease) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.assetURLs == nil {
+		g.assetURLs = make(map[int64]string)
+	}
+	for _, asset := range rel.Assets {
+		g.assetURLs[asset.GetID()] = asset.GetBrowserDownloadURL()
+	}
+}
+
+// LatestRelease returns the most recently released release, upcoming releases excluded.
+func (g *GitLabProvider) LatestRelease(ctx context.Context) (*github.RepositoryRelease, error) {
+	for page := 0; ; {
+		releases, next, err := g.ListReleases(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rel := range releases {
+			if !rel.GetPrerelease() {
+				return rel, nil
+			}
+		}
+
+		if next == 0 {
+			return nil, fmt.Errorf("%w: no release in gitlab project %s", ErrReleaseNotFound, g.Project)
+		}
+		page = next
+	}
+}
+
+func (g *GitLabProvider) ReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	resp, err := g.get(ctx, g.apiURL("/"+url.PathEscape(tag), nil))
+	if errors.Is(err, errGitLabNotFound) {
+		return nil, fmt.Errorf("%w: no release tagged %s", ErrReleaseNotFound, tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var gr gitlabRelease
+	if err := g.decode(resp, &gr); err != nil {
+		return nil, err
+	}
+
+	return g.toRelease(gr), nil
+}
+
+// ListReleases returns a page of releases, sorted from the most recently released.
+func (g *GitLabProvider) ListReleases(ctx context.Context, page int) ([]*github.RepositoryRelease, int, error) {
+	query := url.Values{"per_page": {"100"}, "order_by": {"released_at"}, "sort": {"desc"}}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+
+	resp, err := g.get(ctx, g.apiURL("", query))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	next, _ := strconv.Atoi(resp.Header.Get("X-Next-Page"))
+
+	var grs []gitlabRelease
+	if err := g.decode(resp, &grs); err != nil {
+		return nil, 0, err
+	}
+
+	releases := make([]*github.RepositoryRelease, 0, len(grs))
+	for _, gr := range grs {
+		releases = append(releases, g.toRelease(gr))
+	}
+
+	return releases, next, nil
+}
+
+// DownloadAsset streams the asset link with the given ID, it must belong to a release previously returned by the provider
+// (or by another provider of the same project, through the cache).
+func (g *GitLabProvider) DownloadAsset(ctx context.Context, id int64) (io.ReadCloser, error) {
+	g.mu.Lock()
+	downloadURL, ok := g.assetURLs[id]
+	g.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown gitlab release asset %d", id)
+	}
+
+	resp, err := g.get(ctx, downloadURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,67 @@
+package selfupdater
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// gitlabServer serves a single release, after failing the first requests with the given status.
+func gitlabServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			return
+		}
+
+		json.NewEncoder(w).Encode([]map[string]any{{
+			"tag_name": "v1.1.0",
+			"assets":   map[string]any{"links": []map[string]any{{"id": 1, "name": "repo_linux_amd64", "url": "https://example.com/repo_linux_amd64"}}},
+		}})
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func TestGitLabServerErrorRetried(t *testing.T) {
+	server, requests := gitlabServer(t, 1, http.StatusBadGateway, nil)
+
+	u := New("group", "project", semver.MustParse("1.0.0"), WithProvider(&GitLabProvider{Project: "group/project", BaseURL: server.URL}),
+		WithRetry(3, time.Millisecond))
+	if _, _, err := u.latestRelease(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d requests, expected the server error to be retried once", n)
+	}
+}
+
+func TestGitLabRateLimited(t *testing.T) {
+	server, _ := gitlabServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"30"}})
+
+	u := New("group", "project", semver.MustParse("1.0.0"), WithProvider(&GitLabProvider{Project: "group/project", BaseURL: server.URL}),
+		WithRetry(3, time.Millisecond))
+	_, _, err := u.latestRelease()
+
+	var rateErr *RateLimitError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &rateErr) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if wait := time.Until(rateErr.Reset); wait < 20*time.Second || wait > 30*time.Second {
+		t.Errorf("rate limit resets in %s, expected the Retry-After delay", wait)
+	}
+}
@@ -3,8 +3,18 @@ module github.com/mJehanno/ghr-self-updater
 go 1.21.1
 
 require (
+	github.com/ProtonMail/go-crypto v1.0.0
 	github.com/blang/semver v3.5.1+incompatible
+	github.com/gabstv/go-bsdiff v1.0.5
 	github.com/google/go-github/v59 v59.0.1-0.20240217151021-73422173c633
+	github.com/jedisct1/go-minisign v0.0.0-20230811132847-661be99b8267
+	github.com/ulikunitz/xz v0.5.11
+	golang.org/x/crypto v0.12.0
+	golang.org/x/sys v0.11.0
 )
 
-require github.com/google/go-querystring v1.1.0 // indirect
+require (
+	github.com/cloudflare/circl v1.3.3 // indirect
+	github.com/dsnet/compress v0.0.0-20171208185109-cc9eb1d7ad76 // indirect
+	github.com/google/go-querystring v1.1.0 // indirect
+)
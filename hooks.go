@@ -0,0 +1,73 @@
+package selfupdater
+
+import "fmt"
+
+// Hooks are called at the different stages of [Updater.Update], see [WithHooks]. Each of them is optional.
+// A hook returning an error aborts the update with [ErrAborted].
+type Hooks struct {
+	// BeforeDownload is called once the asset to install is known, before downloading it.
+	BeforeDownload func(plan UpdatePlan) error
+	// AfterDownload is called once the asset is downloaded, verified and extracted, before touching the current executable.
+	AfterDownload func(plan UpdatePlan) error
+	// BeforeSwap is called right before the current executable is replaced.
+	BeforeSwap func(plan UpdatePlan) error
+	// AfterSwap is called once the new executable is installed (and its launch verified), an error rolls the install back
+	// (or removes the new executable on a first install, see [Updater.InstallOnly]).
+	AfterSwap func(plan UpdatePlan) error
+	// OnRollback is called once the old executable is restored after a failed install, with the failure cause.
+	OnRollback func(plan UpdatePlan, cause error)
+}
+
+// WithHooks makes the [Updater] call the given hooks during [Updater.Update], to run custom logic like stopping background workers before the swap.
+func WithHooks(hooks Hooks) UpdaterOpts {
+	return func(u *Updater) {
+		u.hooks = hooks
+	}
+}
+
+// WithConfirm makes the [Updater] ask confirm for approval of the install, once the release is downloaded and verified and right before the current executable is touched.
+// Returning false aborts the update with [ErrAborted] and removes the downloaded release, leaving the current executable as is.
+func WithConfirm(confirm func(plan UpdatePlan) bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.confirm = confirm
+	}
+}
+
+// confirmInstall asks for approval of the install with the callback given to [WithConfirm], if any.
+func (u *Updater) confirmInstall() error {
+	if u.confirm == nil || u.confirm(u.hookPlan()) {
+		return nil
+	}
+
+	u.logger.Info("update declined", "version", u.target.String())
+	return fmt.Errorf("%w: install of %s declined", ErrAborted, u.target.String())
+}
+
+func (u *Updater) hookPlan() UpdatePlan {
+	exePath := u.exePath
+	if exePath == "" {
+		exePath, _ = u.resolveExePath()
+	}
+
+	return UpdatePlan{
+		Current:   u.Current,
+		Target:    u.target,
+		AssetName: u.assetName,
+		AssetSize: u.assetSize,
+		ExePath:   exePath,
+	}
+}
+
+func (u *Updater) runHook(stage string, hook func(plan UpdatePlan) error) error {
+	if hook == nil {
+		return nil
+	}
+
+	err := hook(u.hookPlan())
+	if err != nil {
+		u.logger.Info("update aborted by hook", "stage", stage, "error", err)
+		return fmt.Errorf("%w: %s hook -> %w", ErrAborted, stage, err)
+	}
+
+	return nil
+}
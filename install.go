@@ -0,0 +1,79 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// InstallFromReader installs the binary read from r in place of the current executable, with the same swap, launch verification and rollback
+// as [Updater.Update] (steps 5 to 8), so that you can update from your own source. size is the expected number of bytes, it is not checked if <= 0.
+// Nothing else is verified: checksum and signature verification only apply to release assets.
+func (u *Updater) InstallFromReader(r io.Reader, size int64) error {
+	if u.err != nil {
+		return u.err
+	}
+
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
+	u.setPhase(PhaseInstalling)
+	err = u.installFromReader(r, size)
+	if err != nil {
+		return u.fail(err)
+	}
+	u.setPhase(PhaseDone)
+
+	return nil
+}
+
+func (u *Updater) installFromReader(r io.Reader, size int64) error {
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return err
+	}
+
+	err = u.checkWritable(exePath)
+	if err != nil {
+		return err
+	}
+
+	err = u.prepareTempDir()
+	if err != nil {
+		return err
+	}
+	defer u.fsys.Remove(u.tmpDir)
+
+	u.tmpPath = filepath.Join(u.tmpDir, u.Repo+".install")
+	f, err := u.fsys.Create(u.tmpPath, false, 0666)
+	if err != nil {
+		return fmt.Errorf("%w: failed to create temp binary -> %w", ErrDownloadFailed, err)
+	}
+	// removed unless installed.
+	defer u.fsys.Remove(u.tmpPath)
+
+	written, err := io.Copy(f, &contextReader{ctx: u.ctx, reader: r})
+	errClose := f.Close()
+	if err != nil || errClose != nil {
+		return fmt.Errorf("%w: failed to write temp binary -> %w", ErrDownloadFailed, errors.Join(err, errClose))
+	}
+
+	if size > 0 && written != size {
+		return fmt.Errorf("%w: read %d bytes, expected %d", ErrIncompleteDownload, written, size)
+	}
+
+	// there is no release version to check the binary against.
+	versionParse := u.versionParse
+	u.versionParse = nil
+	defer func() {
+		u.versionParse = versionParse
+	}()
+
+	return u.installNewRelease()
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+
+package selfupdater
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// removeOnReboot is a no-op outside windows: the old binary is kept next to the new one.
+func removeOnReboot(string) error {
+	return nil
+}
+
+// copyOwnership gives filePath the owner and group described by info. It usually requires root to change the owner.
+func copyOwnership(info fs.FileInfo, filePath string) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+
+	return os.Lchown(filePath, int(stat.Uid), int(stat.Gid))
+}
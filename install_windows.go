@@ -0,0 +1,31 @@
+//go:build windows
+
+package selfupdater
+
+import (
+	"fmt"
+	"io/fs"
+
+	"golang.org/x/sys/windows"
+)
+
+// removeOnReboot schedules the deletion of filePath on next boot, as a running (or just renamed) executable can't be deleted on windows.
+// It is a variable so that tests can check what gets scheduled without administrator rights.
+var removeOnReboot = func(filePath string) error {
+	p, err := windows.UTF16PtrFromString(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to schedule removal of %s -> %w", filePath, err)
+	}
+
+	err = windows.MoveFileEx(p, nil, windows.MOVEFILE_DELAY_UNTIL_REBOOT)
+	if err != nil {
+		return fmt.Errorf("failed to schedule removal of %s -> %w", filePath, err)
+	}
+
+	return nil
+}
+
+// copyOwnership is a no-op on windows, where files have no unix owner.
+func copyOwnership(fs.FileInfo, string) error {
+	return nil
+}
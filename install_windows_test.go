@@ -0,0 +1,78 @@
+//go:build windows
+
+package selfupdater
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+func TestInstallWindowsExe(t *testing.T) {
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", map[string][]byte{fmt.Sprintf("repo_%s.exe", runtime.GOARCH): []byte("MZ new")})
+
+	exePath := filepath.Join(t.TempDir(), "repo.exe")
+	if err := os.WriteFile(exePath, []byte("MZ old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithTargetPath(exePath), WithLaunchVerification(false))
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the old executable is renamed away to `.exe.old`, as a running executable can't be replaced.
+	for path, expected := range map[string]string{exePath: "MZ new", exePath + ".old": "MZ old"} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(content, []byte(expected)) {
+			t.Errorf("%s is %q, expected %q", path, content, expected)
+		}
+	}
+}
+
+func TestInstallWindowsKeepsBackup(t *testing.T) {
+	// the test launch needs a real executable, which exits successfully with these arguments.
+	cmd, err := os.ReadFile(filepath.Join(os.Getenv("SystemRoot"), "System32", "cmd.exe"))
+	if err != nil {
+		t.Skip(err)
+	}
+
+	var scheduled []string
+	defer func(orig func(string) error) { removeOnReboot = orig }(removeOnReboot)
+	removeOnReboot = func(filePath string) error {
+		scheduled = append(scheduled, filePath)
+		return nil
+	}
+
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", map[string][]byte{fmt.Sprintf("repo_%s.exe", runtime.GOARCH): cmd})
+
+	exePath := filepath.Join(t.TempDir(), "repo.exe")
+	if err := os.WriteFile(exePath, []byte("MZ old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithTargetPath(exePath), WithBackupRetention(2),
+		WithRelaunchArgs([]string{"/c", "exit", "0"}))
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := u.backupPath(semver.MustParse("1.0.0"))
+	if content, err := os.ReadFile(backup); err != nil || !bytes.Equal(content, []byte("MZ old")) {
+		t.Errorf("%s is %q (%v), expected the old binary", backup, content, err)
+	}
+	// the retained backup must survive the next reboot.
+	if len(scheduled) > 0 {
+		t.Errorf("%v scheduled for removal on reboot, expected none", scheduled)
+	}
+}
@@ -0,0 +1,73 @@
+package selfupdater
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// responseBody is an asset streamed from an HTTP response, whose headers tell how to check its integrity.
+type responseBody struct {
+	io.ReadCloser
+	header http.Header
+}
+
+// contentDigest returns the MD5 digest of the body announced by the response headers: Content-MD5, or an ETag which is a plain MD5
+// (like the ones of S3 for single part uploads). It returns nil if there is none, an ETag may be of any other form.
+func contentDigest(header http.Header) []byte {
+	if digest, err := base64.StdEncoding.DecodeString(header.Get("Content-MD5")); err == nil && len(digest) == md5.Size {
+		return digest
+	}
+
+	etag := header.Get("ETag")
+	if strings.HasPrefix(etag, "W/") {
+		return nil
+	}
+	if digest, err := hex.DecodeString(strings.Trim(etag, `"`)); err == nil && len(digest) == md5.Size {
+		return digest
+	}
+
+	return nil
+}
+
+// digestCheck compares what is read through it with the digest announced by the response the asset is streamed from.
+type digestCheck struct {
+	expected []byte
+	h        hash.Hash
+}
+
+// newDigestCheck returns the reader to stream the asset from, hashing it if reader is a response announcing a digest.
+// The returned check is nil otherwise.
+func newDigestCheck(reader io.Reader) (io.Reader, *digestCheck) {
+	body, ok := reader.(*responseBody)
+	if !ok {
+		return reader, nil
+	}
+
+	expected := contentDigest(body.header)
+	if expected == nil {
+		return reader, nil
+	}
+
+	check := &digestCheck{expected: expected, h: md5.New()}
+	return io.TeeReader(reader, check.h), check
+}
+
+// verify fails with [ErrIncompleteDownload] if the downloaded asset doesn't match the announced digest.
+func (d *digestCheck) verify(assetName string) error {
+	if d == nil {
+		return nil
+	}
+
+	if actual := d.h.Sum(nil); !bytes.Equal(actual, d.expected) {
+		return fmt.Errorf("%w: %s has md5 %x, the server announced %x", ErrIncompleteDownload, assetName, actual, d.expected)
+	}
+
+	return nil
+}
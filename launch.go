@@ -0,0 +1,100 @@
+package selfupdater
+
+import (
+	"os"
+	"os/exec"
+)
+
+// WithRelaunchArgs sets the arguments the new binary is launched with after the update. It defaults to the arguments of the current process (os.Args[1:]).
+// Pass an empty non nil slice to launch it without arguments.
+func WithRelaunchArgs(args []string) UpdaterOpts {
+	return func(u *Updater) {
+		u.relaunchArgs = args
+	}
+}
+
+// WithRelaunchEnv sets the environment the new binary is launched with after the update. It defaults to the environment of the current process.
+func WithRelaunchEnv(env []string) UpdaterOpts {
+	return func(u *Updater) {
+		u.relaunchEnv = env
+	}
+}
+
+// WithLaunchVerification enables or disables the test launch of the new binary once installed (enabled by default).
+// Disable it for long-running services or GUI apps that don't exit on their own. Be aware that without it, a broken binary
+// is not detected and so not automatically rolled back.
+func WithLaunchVerification(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.skipLaunch = !enabled
+		u.launchSet = true
+	}
+}
+
+// WithKeepOldBinary tells whether the `-old` binary is kept once the new one passed its test launch (the default), for a manual rollback
+// or an audit, or removed right away. On windows, where it is still running and can't be removed, its removal is scheduled on next reboot instead.
+// Either way, [Updater.Cleanup] removes it.
+// With [WithBackupRetention], it applies to the backup of the replaced version once the older ones are pruned.
+// It doesn't apply when the test launch is disabled (see [WithLaunchVerification]).
+func WithKeepOldBinary(keep bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.removeOld = !keep
+	}
+}
+
+// WithRollbackVerification makes the [Updater] test launch the old binary once restored by a rollback, the same way as the new one
+// (see [WithLaunchVerification]), so that a broken restored binary is reported with [ErrBrokenRollback] rather than as a successful rollback.
+func WithRollbackVerification(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.verifyRollback = enabled
+	}
+}
+
+func (u *Updater) relaunchArgsAndEnv() ([]string, []string) {
+	args := u.relaunchArgs
+	// the arguments of the current process are meant for it, not for a managed binary.
+	if args == nil && !u.managed {
+		args = os.Args[1:]
+	}
+
+	env := u.relaunchEnv
+	if env == nil {
+		env = os.Environ()
+	}
+
+	return args, env
+}
+
+// launchCommand builds the command launching the binary at exePath the way the current process was invoked, stdio included.
+func launchCommand(exePath string, args, env []string) *exec.Cmd {
+	cmd := exec.Command(exePath, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
+
+func (u *Updater) launchCommand(exePath string) *exec.Cmd {
+	args, env := u.relaunchArgsAndEnv()
+	return launchCommand(exePath, args, env)
+}
+
+// Restart re-executes the binary installed by [Updater.Update] so that the new version is picked up without the user restarting the app.
+// The arguments and environment are the same as for the test launch (see [WithRelaunchArgs] and [WithRelaunchEnv]).
+// On unix, the current process image is replaced (pid, open file descriptors and working directory are kept) so Restart only returns on failure.
+// On windows, the new binary is spawned in the same working directory and the current process exits.
+func (u *Updater) Restart() error {
+	exePath := u.exePath
+	if exePath == "" {
+		var err error
+		exePath, err = u.resolveExePath()
+		if err != nil {
+			return err
+		}
+	}
+
+	args, env := u.relaunchArgsAndEnv()
+
+	return restart(exePath, args, env)
+}
@@ -0,0 +1,45 @@
+package selfupdater
+
+import (
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// WithLibc forces the libc variant (`musl` or `gnu`) of the assets to match, instead of detecting it.
+// Assets whose name doesn't mention any libc are matched whatever the variant.
+func WithLibc(libc string) UpdaterOpts {
+	return func(u *Updater) {
+		u.libc = libc
+	}
+}
+
+// detectLibc tells whether the system uses musl (like alpine) or glibc. It returns an empty string outside linux.
+func detectLibc() string {
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	if matches, _ := filepath.Glob("/lib/ld-musl-*"); len(matches) > 0 {
+		return "musl"
+	}
+
+	return "gnu"
+}
+
+func assetLibc(tokens []string) string {
+	switch {
+	case slices.Contains(tokens, "musl"):
+		return "musl"
+	case slices.Contains(tokens, "gnu"), slices.Contains(tokens, "glibc"):
+		return "gnu"
+	default:
+		return ""
+	}
+}
+
+// matchLibc rejects assets built for another libc variant than the one of the system.
+func (u *Updater) matchLibc(tokens []string) bool {
+	libc := assetLibc(tokens)
+	return u.libc == "" || libc == "" || libc == u.libc
+}
@@ -0,0 +1,62 @@
+package selfupdater
+
+import (
+	"fmt"
+	"io"
+)
+
+// WithMaxDownloadSize makes the [Updater] refuse to download a release asset larger than maxBytes, failing with [ErrAssetTooLarge].
+// The size reported by the release is checked before downloading anything, and the download is aborted as soon as it goes beyond it.
+func WithMaxDownloadSize(maxBytes int64) UpdaterOpts {
+	return func(u *Updater) {
+		u.maxDownloadSize = maxBytes
+	}
+}
+
+// checkAssetSize checks the size reported by the release against the one set with [WithMaxDownloadSize].
+func (u *Updater) checkAssetSize() error {
+	if u.maxDownloadSize > 0 && u.assetSize > u.maxDownloadSize {
+		return fmt.Errorf("%w: %s is %d bytes, more than %d", ErrAssetTooLarge, u.assetName, u.assetSize, u.maxDownloadSize)
+	}
+
+	return nil
+}
+
+// limitDownload makes reading r fail with [ErrAssetTooLarge] past the size set with [WithMaxDownloadSize], offset bytes being already downloaded.
+func (u *Updater) limitDownload(r io.Reader, offset int64) io.Reader {
+	if u.maxDownloadSize <= 0 {
+		return r
+	}
+
+	return &limitedReader{reader: r, remaining: u.maxDownloadSize - offset, u: u}
+}
+
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	u         *Updater
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// one more byte tells whether the limit is exceeded or the asset ends right there.
+	if l.remaining <= 0 {
+		n, err := l.reader.Read(p[:1])
+		if n > 0 {
+			return 0, fmt.Errorf("%w: %s is more than %d bytes", ErrAssetTooLarge, l.u.assetName, l.u.maxDownloadSize)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err := l.reader.Read(p)
+	l.remaining -= int64(n)
+
+	return n, err
+}
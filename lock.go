@@ -0,0 +1,40 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// errLocked is returned by lockFile when the file is locked by another process.
+var errLocked = errors.New("file locked by another process")
+
+// lockUpdate prevents concurrent updates of the executable, by this [Updater] or another process, with a lock file next to it.
+// It fails with [ErrUpdateInProgress] if an update is already running, otherwise the returned function releases the lock, leaving the lock file.
+// The lock file is skipped if it can't be created, the install then fails on its own (see [ErrNotWritable]).
+func (u *Updater) lockUpdate() (func(), error) {
+	if !u.updateMu.TryLock() {
+		return nil, fmt.Errorf("%w: another update is running in this process", ErrUpdateInProgress)
+	}
+
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return u.updateMu.Unlock, nil
+	}
+
+	lockPath := filepath.Join(filepath.Dir(exePath), "."+filepath.Base(exePath)+".lock")
+	release, err := lockFile(u.fsys, lockPath)
+	if errors.Is(err, errLocked) {
+		u.updateMu.Unlock()
+		return nil, fmt.Errorf("%w: %s is locked by another process", ErrUpdateInProgress, lockPath)
+	}
+	if err != nil {
+		u.logger.Debug("failed to lock the update", "path", lockPath, "error", err)
+		return u.updateMu.Unlock, nil
+	}
+
+	return func() {
+		release()
+		u.updateMu.Unlock()
+	}, nil
+}
@@ -0,0 +1,34 @@
+//go:build !windows
+
+package selfupdater
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive lock on the file at path, created if needed, which the system releases if the process dies.
+// Files of a custom [FileSystem] can't be locked, only [os.File]s.
+func lockFile(fsys FileSystem, path string) (func(), error) {
+	created, err := fsys.Create(path, true, 0644)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := created.(*os.File)
+	if !ok {
+		return func() { created.Close() }, nil
+	}
+
+	err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	// the file is kept: removing it would let another process lock a new file at path while a third one still holds the removed one.
+	return func() { f.Close() }, nil
+}
@@ -0,0 +1,33 @@
+package selfupdater
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".app.lock")
+	fsys := osFileSystem{}
+
+	release, err := lockFile(fsys, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lockFile(fsys, path); !errors.Is(err, errLocked) {
+		t.Fatalf("second lock returned %v, expected errLocked", err)
+	}
+
+	release()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("lock file removed on release -> %v", err)
+	}
+
+	release, err = lockFile(fsys, path)
+	if err != nil {
+		t.Fatalf("lock after release failed -> %v", err)
+	}
+	release()
+}
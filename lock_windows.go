@@ -0,0 +1,39 @@
+//go:build windows
+
+package selfupdater
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes an exclusive lock on the file at path, created if needed, which the system releases if the process dies.
+// Files of a custom [FileSystem] can't be locked, only [os.File]s.
+func lockFile(fsys FileSystem, path string) (func(), error) {
+	created, err := fsys.Create(path, true, 0644)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := created.(*os.File)
+	if !ok {
+		return func() { created.Close() }, nil
+	}
+
+	ol := new(windows.Overlapped)
+	err = windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK|windows.LOCKFILE_FAIL_IMMEDIATELY, 0, 1, 0, ol)
+	if err != nil {
+		f.Close()
+		if errors.Is(err, windows.ERROR_LOCK_VIOLATION) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	return func() {
+		windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, ol)
+		// the file is kept: removing it would let another process lock a new file at path while a third one still holds the removed one.
+		f.Close()
+	}, nil
+}
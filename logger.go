@@ -0,0 +1,24 @@
+package selfupdater
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the [Updater] log each step of the update process (debug and info levels) to logger.
+// Nothing is logged by default.
+func WithLogger(logger *slog.Logger) UpdaterOpts {
+	return func(u *Updater) {
+		if logger != nil {
+			u.logger = logger
+		}
+	}
+}
+
+// discardHandler is the default [slog.Handler] of the [Updater], it drops every record.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -6,30 +6,119 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
 	"net/http"
-	"os"
-	"os/exec"
-	"path"
+	"path/filepath"
+	"regexp"
 	"runtime"
-	"slices"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/google/go-github/v59/github"
 )
 
 type repositoryInfo struct {
-	ctx      context.Context
-	gclient  *github.Client
-	assets   []*github.ReleaseAsset
-	platform string
+	ctx           context.Context
+	cancel        context.CancelFunc
+	timeout       time.Duration
+	httpClient    *http.Client
+	proxyURL      string
+	token         string
+	tokenSource   func() (string, error)
+	userAgent     string
+	enterpriseURL string
+	uploadURL     string
+	gclient       *github.Client
+	provider      ReleaseProvider
+	mirrors       []RepoRef
+	assets        []*github.ReleaseAsset
+	target        semver.Version
+	latest        *ReleaseInfo
+	goos          string
+	goarch        string
+	goarm         string
+	platform      string
+	libc          string
+	crossPlatform bool
+	assetMatcher  func(name string) bool
+	assetPattern  *regexp.Regexp
+	fallbacks     []string
+	assetTemplate string
+	assetTmpl     *template.Template
+	assetSelector func(candidates []*github.ReleaseAsset) *github.ReleaseAsset
+	contentType   string
+	newerVersion  func(latest, current semver.Version) bool
+	tagParser     func(tag string) (semver.Version, error)
+	releaseFilter func(rel ReleaseInfo) bool
+	prereleases   bool
+	drafts        bool
+	constraint    string
+	versionRange  semver.Range
+	manifestName  string
+	manifest      *Manifest
+	policy        UpdatePolicy
+	cacheTTL      time.Duration
+}
+
+type verificationInfo struct {
+	checksum            bool
+	checksumRequired    bool
+	checksumAlgo        string
+	gpgKey              []byte
+	minisignKey         string
+	cosign              *CosignOptions
+	slsa                *SLSAOptions
+	authenticodeSubject string
+	codesignIdentity    string
+}
+
+type downloadInfo struct {
+	progress         func(downloaded, total int64)
+	detailedProgress func(p Progress)
+	deltaUpdates     bool
+	downloadParts    int
+	retryAttempts    int
+	retryBackoff     time.Duration
+	rateLimitWait    bool
+	downloadHeaders  map[string]string
+	inMemory         bool
+	directDownload   bool
+	maxDownloadSize  int64
+	memAsset         []byte
 }
 
 type installInfo struct {
-	assetID   int64
-	assetName string
-	tmpPath   string
-	exePath   string
+	assetID         int64
+	assetName       string
+	assetSize       int64
+	assetURL        string
+	binaryName      string
+	tempDir         string
+	tmpDir          string
+	tmpPath         string
+	exePath         string
+	targetPath      string
+	managed         bool
+	managedPattern  *regexp.Regexp
+	relaunchArgs    []string
+	relaunchEnv     []string
+	skipLaunch      bool
+	launchSet       bool
+	foreignInstall  bool
+	verifyRollback  bool
+	removeOld       bool
+	serviceManager  ServiceManager
+	service         string
+	backupRetention int
+	hooks           Hooks
+	confirm         func(plan UpdatePlan) bool
+	staged          string
+	versionArgs     []string
+	versionParse    func(output string) (semver.Version, error)
 }
 
 // Updater is the main structure in charge to check latest version and update your app.
@@ -37,8 +126,19 @@ type Updater struct {
 	Owner   string
 	Repo    string
 	Current semver.Version
+	// Installed is the version installed by the last successful update, Current is also set to it.
+	Installed semver.Version
 	repositoryInfo
+	verificationInfo
+	downloadInfo
 	installInfo
+	logger   *slog.Logger
+	fsys     FileSystem
+	err      error
+	updateMu sync.Mutex
+	statusMu sync.Mutex
+	status   UpdateStatus
+	rate     rateMeter
 }
 
 // UpdaterOpts represent an option you can pass to [Updater] constructor.
@@ -55,14 +155,30 @@ func WithContext(ctx context.Context) UpdaterOpts {
 // WithHttpClient will pass the given *http.Client to an [Updater] instance.
 func WithHttpClient(client *http.Client) UpdaterOpts {
 	return func(u *Updater) {
-		u.gclient = github.NewClient(client)
+		u.httpClient = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests made to github, asset downloads included, instead of the go-github one.
+func WithUserAgent(ua string) UpdaterOpts {
+	return func(u *Updater) {
+		u.userAgent = ua
+	}
+}
+
+// WithToken will make the [Updater] authenticate against github with the given personal access token.
+// It is required to update from a private repository. An empty token keeps the client unauthenticated.
+func WithToken(token string) UpdaterOpts {
+	return func(u *Updater) {
+		u.token = token
 	}
 }
 
 // New creates a new instance of Updater.
 // It needs the owner and repo name to work and the current version of your app (in semver format ->  [semver package])
-// You can pass some options (WithContext, WithHttpClient) so that the updater can fits your need.
+// You can pass some options (WithContext, WithHttpClient, WithToken, ...) so that the updater can fits your need.
 // If you don't, the Updater will use context.Background and http.DefaultClient by default.
+// If an option is invalid (e.g. a malformed enterprise URL), every call to the Updater fails with the corresponding error, use [Updater.Err] to check it right away.
 // [semver package]: https://github.com/blang/semver
 func New(owner, repo string, current semver.Version, options ...UpdaterOpts) *Updater {
 	u := &Updater{
@@ -70,162 +186,955 @@ func New(owner, repo string, current semver.Version, options ...UpdaterOpts) *Up
 		Repo:    repo,
 		Current: current,
 		repositoryInfo: repositoryInfo{
-			ctx:      context.Background(),
-			gclient:  github.NewClient(http.DefaultClient),
-			platform: fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH),
+			ctx:        context.Background(),
+			httpClient: http.DefaultClient,
+			goos:       runtime.GOOS,
+			goarch:     runtime.GOARCH,
+			platform:   fmt.Sprintf("%s-%s", runtime.GOOS, runtime.GOARCH),
+			goarm:      detectARM(),
+			libc:       detectLibc(),
 		},
+		logger: slog.New(discardHandler{}),
+		fsys:   osFileSystem{},
 	}
 
 	for _, optn := range options {
 		optn(u)
 	}
+	u.applyManagedDefaults()
+
+	var err error
+	u.httpClient, u.err = withProxy(u.httpClient, u.proxyURL)
+	u.gclient, err = u.newGithubClient()
+	if u.err == nil {
+		u.err = err
+	}
+	if u.err == nil {
+		u.err = validateChecksumAlgo(u.checksumAlgo)
+	}
+	if u.err == nil {
+		u.err = validateCosign(u.cosign)
+	}
+	if u.err == nil {
+		u.err = validateSLSA(u.slsa)
+	}
+	if u.err == nil {
+		u.versionRange, u.err = parseConstraint(u.constraint)
+	}
+	if u.err == nil {
+		u.assetTmpl, u.err = parseAssetTemplate(u.assetTemplate)
+	}
+	if u.provider == nil {
+		u.provider = &githubProvider{client: u.gclient, httpClient: u.httpClient, owner: u.Owner, repo: u.Repo, headers: u.downloadHeaders}
+	}
+	if u.err == nil {
+		u.provider, u.err = u.withMirrors(u.provider)
+	}
 
 	return u
 }
 
+// Err returns the configuration error of the [Updater], if any. It is nil when every option given to [New] is valid.
+func (u *Updater) Err() error {
+	return u.err
+}
+
+func (u *Updater) newGithubClient() (*github.Client, error) {
+	httpClient := u.httpClient
+	if u.tokenSource != nil {
+		httpClient = withTokenSource(httpClient, u.tokenSource)
+	}
+
+	client := github.NewClient(httpClient)
+	if u.token != "" && u.tokenSource == nil {
+		client = client.WithAuthToken(u.token)
+	}
+	if u.userAgent != "" {
+		client.UserAgent = u.userAgent
+	}
+
+	if u.enterpriseURL != "" {
+		return withEnterpriseURLs(client, u.enterpriseURL, u.uploadURL)
+	}
+
+	return client, nil
+}
+
+// UpdateAvailable is the result of [Updater.CheckForUpdate].
+type UpdateAvailable struct {
+	// Available tells whether Latest is newer than Current, build metadata being ignored (`1.0.0+b` is not newer than `1.0.0+a`).
+	Available bool
+	Current   semver.Version
+	Latest    semver.Version
+	// ReleaseURL is the web page of the latest release.
+	ReleaseURL string
+	// AssetName is the asset [Updater.Update] would install, empty if none matches the current platform.
+	AssetName string
+}
+
+// CheckForUpdate fetches the latest release and tells whether it is newer than the current version, along with what is needed to prompt the user.
+// It makes the same single API call as [Updater.CheckLatest]. If the newer release has no assets yet, it fails with [ErrNoAssetsYet].
+func (u *Updater) CheckForUpdate() (*UpdateAvailable, error) {
+	defer u.withDeadline()()
+
+	u.setPhase(PhaseChecking)
+	var (
+		rel      *github.RepositoryRelease
+		latest   semver.Version
+		fetchErr error
+	)
+	// only the missing assets are retried here, fetching the release is already retried.
+	err := u.retry(func() error {
+		rel, latest, fetchErr = u.latestRelease()
+		if fetchErr == nil && len(rel.Assets) == 0 && u.isNewer(latest, u.Current) {
+			return fmt.Errorf("%w: release %s", ErrNoAssetsYet, rel.GetTagName())
+		}
+		return nil
+	})
+	if fetchErr != nil {
+		return nil, u.fail(fetchErr)
+	}
+	if err != nil {
+		return nil, u.fail(err)
+	}
+	u.setPhase(PhaseIdle)
+
+	u.assets = rel.Assets
+	u.target = latest
+
+	available := &UpdateAvailable{
+		Available:  u.isNewer(latest, u.Current),
+		Current:    u.Current,
+		Latest:     latest,
+		ReleaseURL: rel.GetHTMLURL(),
+	}
+	if asset, err := u.getAsset(); err == nil {
+		available.AssetName = asset.GetName()
+	}
+
+	return available, nil
+}
+
 // CheckLatest will check if the current version is the latest.
 // It returns a boolean and an error.
-// To avoid wrong behaviour, it returns true if an error is encountered.
+// If an error is encountered, it returns false along with the error: the check failed,
+// which must not be mistaken for being up to date, so always check the error first.
+// See [Updater.CheckForUpdate] to get the latest version as well.
 func (u *Updater) CheckLatest() (bool, error) {
-	rel, _, err := u.gclient.Repositories.GetLatestRelease(u.ctx, u.Owner, u.Repo)
+	available, err := u.CheckForUpdate()
 	if err != nil {
-		return true, err
+		return false, err
 	}
 
-	latest, err := semver.Parse(strings.ReplaceAll(rel.GetTagName(), "v", ""))
-	if err != nil {
-		return true, err
+	return !available.Available, nil
+}
+
+func (u *Updater) getReleaseByVersion(v semver.Version) (*github.RepositoryRelease, error) {
+	if u.err != nil {
+		return nil, u.err
 	}
 
-	u.assets = rel.Assets
+	for _, tag := range []string{"v" + v.String(), v.String()} {
+		var rel *github.RepositoryRelease
+		err := u.retry(func() (err error) {
+			rel, err = u.provider.ReleaseByTag(u.ctx, tag)
+			return err
+		})
+		if err == nil {
+			return rel, nil
+		}
 
-	return latest.LTE(u.Current), nil
+		if !errors.Is(err, ErrReleaseNotFound) {
+			return nil, fmt.Errorf("failed to retrieve release %s -> %w", tag, err)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: no release tagged v%s", ErrReleaseNotFound, v)
 }
 
 func (u *Updater) getAsset() (*github.ReleaseAsset, error) {
-	index := slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
-		return strings.Contains(*ra.Name, u.platform)
-	})
+	if u.manifest != nil {
+		return u.manifestAsset()
+	}
+
+	match, searched, err := u.assetMatch()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.assets) == 0 {
+		return nil, fmt.Errorf("%w: release %s", ErrNoAssetsYet, u.target)
+	}
+
+	var candidates []*github.ReleaseAsset
+	for _, ra := range u.assets {
+		if match(ra.GetName()) {
+			candidates = append(candidates, ra)
+		}
+	}
+
+	if len(candidates) == 0 {
+		var token string
+		candidates, token = u.fallbackCandidates()
+		if len(candidates) > 0 {
+			u.logger.Info("release asset matched with a fallback token", "platform", u.platform, "token", token)
+		}
+	}
+
+	if len(candidates) == 0 {
+		u.logger.Debug("no release asset matching platform", "platform", u.platform, "assets", len(u.assets))
+		return nil, fmt.Errorf("%w: no asset %s; available: [%s]", ErrAssetNotFound, searched, strings.Join(assetNames(u.assets), ", "))
+	}
+
+	candidates, err = u.filterContentType(candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	u.preferARMVersion(candidates)
+
+	asset := candidates[0]
+	if len(candidates) > 1 && u.assetSelector != nil {
+		asset = u.assetSelector(candidates)
+		if asset == nil {
+			return nil, fmt.Errorf("%w: no asset selected among [%s]", ErrAssetNotFound, strings.Join(assetContentTypes(candidates), ", "))
+		}
+	} else if u.ambiguous(candidates) {
+		return nil, fmt.Errorf("%w: several assets %s among [%s], see WithAssetSelector", ErrAssetNotFound, searched, strings.Join(assetContentTypes(candidates), ", "))
+	}
+
+	u.logger.Debug("release asset matched", "platform", u.platform, "asset", asset.GetName(), "candidates", len(candidates))
+
+	return asset, nil
+}
 
-	if index == -1 {
-		err := fmt.Errorf("release asset not found")
+func (u *Updater) openAsset(id int64) (io.ReadCloser, error) {
+	var (
+		reader io.ReadCloser
+		err    error
+	)
+	// an asset listed in the manifest (see [WithManifest]) may not be part of the release.
+	if id == 0 && u.assetURL != "" {
+		reader, err = u.downloadURL(u.assetURL)
+	} else if direct, ok := u.openDirect(id); ok {
+		reader = direct
+	} else {
+		reader, err = u.provider.DownloadAsset(u.ctx, id)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: failed to download release asset -> %w", ErrDownloadFailed, err)
 		return nil, err
 	}
 
-	return u.assets[index], nil
+	return reader, nil
 }
 
 func (u *Updater) downloadAsset() error {
-	reader, redirect, err := u.gclient.Repositories.DownloadReleaseAsset(u.ctx, u.Owner, u.Repo, u.assetID, u.gclient.Client())
+	u.tmpPath = filepath.Join(u.tmpDir, u.assetName)
+	// the asset is written to a `.part` file renamed once complete, so that a partial download is never taken for a complete one.
+	partPath := u.partPath()
+	u.removeStaleParts()
+
+	// a smaller file left by a previous attempt is resumed.
+	var offset int64
+	if info, err := u.fsys.Stat(partPath); err == nil && u.assetSize > 0 && info.Size() < u.assetSize {
+		offset = info.Size()
+	}
+
+	reader, resumed, err := u.openAssetFrom(u.assetID, offset)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if resumed {
+		u.logger.Info("resuming release asset download", "asset", u.assetName, "size", u.assetSize, "offset", offset, "path", partPath)
+	} else {
+		offset = 0
+		u.logger.Info("downloading release asset", "asset", u.assetName, "size", u.assetSize, "path", partPath)
+	}
+
+	f, err := u.fsys.Create(partPath, resumed, 0666)
 	if err != nil {
-		err = fmt.Errorf("failed to download release asset -> %w", err)
+		err = fmt.Errorf("%w: failed to create temp downloaded release asset -> %w", ErrDownloadFailed, err)
 		return err
 	}
+	defer f.Close()
 
-	if redirect != "" {
-		return fmt.Errorf("failed to handle redirect url")
+	// a resumed download is only partially streamed, it can't be checked against the digest of the whole asset.
+	var (
+		body   io.Reader = reader
+		digest *digestCheck
+	)
+	if !resumed {
+		body, digest = newDigestCheck(reader)
 	}
 
-	u.tmpPath = path.Join(os.TempDir(), u.assetName)
+	progress := newProgressReader(&contextReader{ctx: u.ctx, reader: u.limitDownload(body, offset)}, u.assetSize, u.reportProgress)
+	progress.downloaded = offset
 
-	f, err := os.Create(u.tmpPath)
+	written, err := io.Copy(f, progress)
+	downloaded := offset + written
 	if err != nil {
-		err = fmt.Errorf("failed to create temp downloaded release asset -> %w", err)
+		f.Close()
+		// a cancelled or oversized download is not meant to be resumed, any other failure keeps the partial file for the next attempt.
+		if u.ctx.Err() != nil || errors.Is(err, ErrAssetTooLarge) {
+			u.fsys.Remove(partPath)
+		}
+		u.logger.Debug("release asset download failed", "asset", u.assetName, "bytes", downloaded, "error", err)
+		if errors.Is(err, ErrAssetTooLarge) {
+			return err
+		}
+		err = fmt.Errorf("%w: failed to write downloaded release asset -> %w", ErrDownloadFailed, err)
 		return err
 	}
 
-	defer func() {
+	if u.assetSize > 0 && downloaded != u.assetSize {
 		f.Close()
-		reader.Close()
-	}()
+		if downloaded > u.assetSize {
+			u.fsys.Remove(partPath)
+		}
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrIncompleteDownload, u.assetName, downloaded, u.assetSize)
+	}
 
-	_, err = io.Copy(f, reader)
+	err = digest.verify(u.assetName)
 	if err != nil {
-		err = fmt.Errorf("failed to write downloaded release asset -> %w", err)
+		f.Close()
+		u.fsys.Remove(partPath)
 		return err
 	}
+
+	err = f.Close()
+	if err == nil {
+		err = u.fsys.Rename(partPath, u.tmpPath)
+	}
+	if err != nil {
+		u.fsys.Remove(partPath)
+		return fmt.Errorf("%w: failed to write downloaded release asset -> %w", ErrDownloadFailed, err)
+	}
+
+	if resumed && !u.checksum {
+		// the bytes of the previous attempt are not covered by the digest, the checksum is checked even if verification is not enabled
+		// as long as the release provides it.
+		err = u.checkChecksum(false)
+		if err != nil {
+			u.fsys.Remove(u.tmpPath)
+			return err
+		}
+	}
+
+	progress.done()
+	u.logger.Info("release asset downloaded", "asset", u.assetName, "bytes", downloaded)
+	return nil
+}
+
+// oldPath returns the path the current binary is archived to while the new one is installed.
+// A running executable can't be overwritten on windows, it can only be renamed, hence the `.exe.old` name there.
+func (u *Updater) oldPath() string {
+	if u.backupRetention > 0 {
+		return u.backupPath(u.Current)
+	}
+	return u.singleOldPath()
+}
+
+// singleOldPath is the path of the old binary when no versioned backups are kept (see [WithBackupRetention]).
+func (u *Updater) singleOldPath() string {
+	if runtime.GOOS == "windows" {
+		return u.exePath + ".old"
+	}
+	return fmt.Sprintf("%s-old", u.exePath)
+}
+
+// rollback restores the archived old binary at exePath. It must only be called once the test launch has exited.
+// The archived binary is the source of truth: nothing is removed if it is missing, so that the new binary is kept rather than nothing.
+func (u *Updater) rollback() error {
+	old := u.oldPath()
+	u.logger.Info("rolling back to the old binary", "old", old, "path", u.exePath)
+
+	if _, err := u.fsys.Stat(old); err != nil {
+		return fmt.Errorf("failed to find the old binary -> %w", err)
+	}
+
+	// renaming over the new binary replaces it in a single step, removing it first is only a fallback.
+	err := u.fsys.Rename(old, u.exePath)
+	if err != nil {
+		errRem := u.fsys.Remove(u.exePath)
+		if errRem != nil && !errors.Is(errRem, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove the new downloaded binary (%w) -> %w", errRem, err)
+		}
+
+		err = u.fsys.Rename(old, u.exePath)
+		if err != nil {
+			return fmt.Errorf("failed to rename back the old binary -> %w", err)
+		}
+	}
+
+	return checkExecutable(u.fsys, u.exePath)
+}
+
+func checkExecutable(fsys FileSystem, filePath string) error {
+	info, err := fsys.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to stat restored binary -> %w", err)
+	}
+
+	if !info.Mode().IsRegular() || runtime.GOOS != "windows" && info.Mode().Perm()&0111 == 0 {
+		return fmt.Errorf("restored binary %s is not executable", filePath)
+	}
+
 	return nil
 }
 
-func (u *Updater) rollack() error {
-	errRem := os.Remove(u.exePath)
-	if errRem != nil {
-		errRem = fmt.Errorf("failed to remove the new downloaded binary -> %w", errRem)
+// failInstall rolls back the install after a failure happening once the old binary has been archived.
+func (u *Updater) failInstall(msg string, err error) error {
+	errRoll := u.rollback()
+	if errRoll != nil {
+		return fmt.Errorf("%w: failed to rollback (%w) after %s -> %w", ErrRollbackFailed, errRoll, msg, err)
 	}
-	errRen := os.Rename(fmt.Sprintf("%s-old", u.exePath), u.exePath)
-	if errRen != nil {
-		errRen = fmt.Errorf("failed to rename back the old binary -> %w", errRen)
+
+	if u.verifyRollback {
+		u.logger.Info("launching the restored binary", "path", u.exePath)
+		errLaunch := u.launchCommand(u.exePath).Run()
+		if errLaunch != nil {
+			return fmt.Errorf("%w: restored binary fails to launch (%w) after %s -> %w", ErrBrokenRollback, errLaunch, msg, err)
+		}
 	}
 
-	return errors.Join(errRem, errRen)
+	if u.hooks.OnRollback != nil {
+		u.hooks.OnRollback(u.hookPlan(), err)
+	}
+	return fmt.Errorf("%w: rolled back after %s -> %w", ErrInstallFailed, msg, err)
 }
 
 func (u *Updater) installNewRelease() error {
-	exePath, err := os.Executable()
+	exePath, err := u.resolveExePath()
 	if err != nil {
-		return fmt.Errorf("failed to retrieve current executable path -> %w", err)
+		return fmt.Errorf("%w: %w", ErrInstallFailed, err)
 	}
 	u.exePath = exePath
 
-	err = os.Rename(exePath, fmt.Sprintf("%s-old", exePath))
+	err = u.checkPlatform()
+	if err == nil {
+		err = checkBinaryFormat(u.fsys, u.tmpPath, u.goos)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to rename the old binary -> %w", err)
+		u.fsys.Remove(u.tmpPath)
+		return err
 	}
 
-	err = os.Rename(u.tmpPath, exePath)
+	// the new binary gets the mode and ownership of the one it replaces.
+	mode := fs.FileMode(0775)
+	oldInfo, errStat := u.fsys.Stat(exePath)
+	if errStat == nil {
+		mode = oldInfo.Mode().Perm()
+	}
+
+	err = u.runHook("before swap", u.hooks.BeforeSwap)
+	if err == nil {
+		err = u.confirmInstall()
+	}
+	if err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return err
+	}
+
+	// the new binary is staged in the target directory, so that replacing the old one is a single rename on the same filesystem.
+	newPath := filepath.Join(filepath.Dir(exePath), "."+filepath.Base(exePath)+".new")
+	u.logger.Debug("staging the new binary", "from", u.tmpPath, "path", newPath)
+	err = moveFile(u.fsys, u.tmpPath, newPath)
+	if err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return fmt.Errorf("%w: failed to move the new binary next to the old one -> %w", ErrInstallFailed, err)
+	}
+	if runtime.GOOS != "windows" {
+		err = u.fsys.Chmod(newPath, mode)
+		if err != nil {
+			u.fsys.Remove(newPath)
+			return fmt.Errorf("%w: failed to add executable permission on binary -> %w", ErrInstallFailed, err)
+		}
+
+		if errStat == nil && !u.customFileSystem() {
+			if err := copyOwnership(oldInfo, newPath); err != nil {
+				u.logger.Debug("failed to keep the ownership of the old binary", "path", exePath, "error", err)
+			}
+		}
+	}
+
+	err = u.prepareMacBinary(newPath)
 	if err != nil {
-		return fmt.Errorf("failed to rename the new binary with the old name -> %w", err)
+		u.fsys.Remove(newPath)
+		return err
 	}
-	if strings.Contains(u.platform, "linux") {
-		err = os.Chmod(exePath, 0775)
+
+	// there is no old binary on a first install.
+	firstInstall := errors.Is(errStat, fs.ErrNotExist)
+	movedAway := false
+	if !firstInstall {
+		u.logger.Debug("archiving the old binary", "path", exePath, "old", u.oldPath())
+		movedAway = runtime.GOOS == "windows"
+		if movedAway {
+			err = moveFile(u.fsys, exePath, u.oldPath())
+		} else {
+			// the old binary stays in place until the rename below replaces it.
+			err = linkOrCopy(u.fsys, exePath, u.oldPath())
+		}
 		if err != nil {
-			return fmt.Errorf("failed to add executable permission on binary -> %w", err)
+			u.fsys.Remove(newPath)
+			return fmt.Errorf("%w: failed to archive the old binary -> %w", ErrInstallFailed, err)
 		}
 	}
-	err = exec.Command(exePath).Run()
+
+	u.logger.Debug("installing the new binary", "from", newPath, "path", exePath)
+	err = u.fsys.Rename(newPath, exePath)
 	if err != nil {
-		errRoll := u.rollack()
-		return fmt.Errorf("failed to rollback (%w) after unsuccessful try on launching new binary -> %w", errRoll, err)
+		u.fsys.Remove(newPath)
+		if !movedAway {
+			// the old binary was not replaced.
+			return fmt.Errorf("%w: failed to rename the new binary with the old name -> %w", ErrInstallFailed, err)
+		}
+		return u.failInstall("failure to rename the new binary with the old name", err)
+	}
+
+	// a first install has no old binary to roll back to, the new one is removed instead.
+	fail := func(msg string, err error) error {
+		if !firstInstall {
+			return u.failInstall(msg, err)
+		}
+		u.fsys.Remove(exePath)
+		return fmt.Errorf("%w: removed the new binary after %s -> %w", ErrInstallFailed, msg, err)
+	}
+
+	if !u.skipLaunch && u.serviceManager == ServiceNone {
+		if detected := detectServiceManager(); detected != ServiceNone {
+			u.logger.Warn("running under a service manager, the test launch may conflict with it, see WithServiceManager", "manager", detected.String())
+		}
+
+		u.logger.Info("launching the new binary", "path", exePath)
+		// Run waits for the test launch to exit, so the new binary is not busy anymore if a rollback is needed.
+		err = u.launchCommand(exePath).Run()
+		if err != nil {
+			return fail("unsuccessful try on launching new binary", err)
+		}
+	}
+
+	// the new binary is never run on a first install (see InstallOnly), there is nothing to roll back to anyway.
+	if !firstInstall {
+		err = u.checkInstalledVersion(exePath)
+		if err != nil {
+			return u.failInstall("version check failure", err)
+		}
+	}
+
+	err = u.runHook("after swap", u.hooks.AfterSwap)
+	if err != nil {
+		return fail("after swap hook failure", err)
+	}
+
+	u.pruneBackups()
+
+	if u.serviceManager != ServiceNone {
+		return u.restartService()
+	}
+
+	if u.skipLaunch {
+		return nil
+	}
+
+	if u.removeOld {
+		err = u.fsys.Remove(u.oldPath())
+		if err == nil {
+			return nil
+		}
+		// the old binary of the running process can't be removed on windows.
+		u.logger.Debug("failed to remove the old binary", "path", u.oldPath(), "error", err)
+
+		// the versioned backups are retained on purpose (see WithBackupRetention), only the single old binary is scheduled for removal.
+		if u.backupRetention == 0 {
+			// best effort: scheduling the removal requires administrator rights on windows.
+			_ = removeOnReboot(u.singleOldPath())
+		}
 	}
 
 	return nil
 }
 
 // Update will perfom the update process which means :
-// 1. Retrieve the corresponding asset (based on platform - os/arch - it needs to appear in the name like `my-super-app_linux-amd64`).
-// 2. Download latest release asset for the current platform (os/arch).
-// 3. Rename the current process executable with a `-old` suffix.
-// 4. Give execution permission to the new executable.
-// 5. Try to launch the new executable.
-// 6. Try to rollack if it fails by removing the download executable and remove the `-old` suffix.
+// 1. Retrieve the corresponding asset of the newer release found by [Updater.CheckForUpdate], or else of the latest release if it is newer (based on platform - os/arch - it needs to appear in the name like `my-super-app_linux-amd64`,
+// common variations like `linux_x86_64` or `amd64.linux` are also recognized, see [WithAssetMatcher] for custom matching).
+// 2. Download latest release asset for the current platform (os/arch), or patch the current executable if enabled (see [WithDeltaUpdates]).
+// 3. Verify the downloaded asset checksum and signature if enabled (see [WithChecksumVerification], [WithGPGPublicKey], [WithMinisignPublicKey] and [WithCosignVerification]), and its SLSA provenance (see [WithSLSAVerification]).
+// 4. Extract the binary if the asset is an archive (`.tar.gz`, `.tgz`, `.tar.xz` or `.zip`, see [WithArchiveBinaryName]) or decompress it (`.gz`, `.xz`), and check its Authenticode signature on windows if enabled (see [WithAuthenticodeVerification]).
+// 5. Move the new executable next to the current one, with the permissions and ownership of the current one (and without quarantine on macOS, see [WithCodesignIdentity]).
+// 6. Keep the current executable with a `-old` suffix (`.exe.old` on windows, where it is deleted on next reboot once the update succeeded) and replace it with the new one
+// in a single rename, so that a crash never leaves the executable path empty (on windows, where a running executable can't be replaced, it is renamed away first).
+// 7. Try to launch the new executable, unless disabled with [WithLaunchVerification] (with the current process arguments and environment, see [WithRelaunchArgs] and [WithRelaunchEnv]).
+// 8. Try to rollback if it fails (or if the new executable doesn't report the expected version, see [WithVersionCheckCommand]) by restoring the `-old` binary over the downloaded one.
+//
+// The hooks given with [WithHooks] are called along the way.
+// It fails with [ErrUpdateInProgress] if another update of the same executable is running, in this process or another one.
 func (u *Updater) Update() error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
+	// without a newer release found by a check for update beforehand (not called, or that release is now installed), the latest one is resolved.
+	if u.err == nil && !u.isNewer(u.target, u.Current) {
+		upToDate, err := u.resolveUpdate()
+		if err != nil || upToDate {
+			return err
+		}
+	}
+
+	return u.update()
+}
+
+// resolveUpdate fetches the latest release for [Updater.Update], it tells whether the current version is up to date.
+func (u *Updater) resolveUpdate() (bool, error) {
+	u.setPhase(PhaseChecking)
+	rel, latest, err := u.latestRelease()
+	if err != nil {
+		return false, u.fail(err)
+	}
+
+	u.assets = rel.Assets
+	u.target = latest
+	if !u.isNewer(latest, u.Current) {
+		u.logger.Info("already up to date", "version", u.Current.String(), "latest", latest.String())
+		u.setPhase(PhaseIdle)
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (u *Updater) update() error {
+	if u.err != nil {
+		return u.err
+	}
+
+	defer u.withDeadline()()
+
+	err := u.download()
+	if err != nil {
+		return err
+	}
+
+	return u.install()
+}
+
+// Download performs the first half of [Updater.Update] (steps 1 to 4): the release asset is downloaded, verified and extracted in a temp directory,
+// without touching the current executable. Call [Updater.Apply] to install it at a safe moment, like when a service is shutting down.
+// Like [Updater.Update], it resolves the latest release if no newer one was found by a check for update beforehand, and downloads nothing
+// (leaving nothing to apply) if the current version is up to date.
+func (u *Updater) Download() error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
+	if u.err == nil && !u.isNewer(u.target, u.Current) {
+		upToDate, err := u.resolveUpdate()
+		if err != nil || upToDate {
+			return err
+		}
+	}
+
+	return u.download()
+}
+
+func (u *Updater) download() (err error) {
+	if u.err != nil {
+		return u.err
+	}
+
+	defer u.withDeadline()()
+
+	u.staged = ""
+	u.setPhase(PhaseDownloading)
+	defer func() {
+		if err != nil {
+			u.fail(err)
+			return
+		}
+		u.setPhase(PhaseReady)
+	}()
+
 	asset, err := u.getAsset()
 	if err != nil {
 		return err
 	}
 
-	u.assetID = asset.GetID()
-	u.assetName = asset.GetName()
+	u.setAsset(asset)
+
+	err = u.checkAssetSize()
+	if err != nil {
+		return err
+	}
+
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return err
+	}
+
+	// fail before downloading anything if the update can't be installed.
+	err = u.checkWritable(exePath)
+	if err != nil {
+		return err
+	}
+
+	err = u.checkPlatform()
+	if err != nil {
+		return err
+	}
+
+	if !u.inMemory {
+		err = u.prepareTempDir()
+		if err != nil {
+			return err
+		}
+	}
+	defer func() {
+		if err != nil {
+			u.cleanupDownload()
+		}
+	}()
+
+	err = u.runHook("before download", u.hooks.BeforeDownload)
+	if err != nil {
+		return err
+	}
+
+	err = errNoPatch
+	if u.deltaUpdates && !u.inMemory {
+		err = u.downloadPatched()
+		if err != nil && !errors.Is(err, errNoPatch) {
+			u.logger.Info("delta update failed, falling back to full download", "error", err)
+		}
+	}
+
+	if err != nil {
+		err = u.retry(u.fetchAsset)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = u.verifyAsset()
+	if err != nil {
+		return err
+	}
+
+	err = u.extractBinary()
+	if err != nil {
+		return err
+	}
+
+	err = u.verifyAuthenticode()
+	if err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return err
+	}
+
+	err = u.runHook("after download", u.hooks.AfterDownload)
+	if err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return err
+	}
+
+	u.staged = u.tmpPath
+
+	return nil
+}
+
+// verifyAsset runs every configured verification against the downloaded asset, removing it if one fails.
+func (u *Updater) verifyAsset() error {
+	for _, verify := range []func() error{u.verifyManifestChecksum, u.verifyChecksum, u.verifyGPGSignature, u.verifyMinisignSignature, u.verifyCosignSignature, u.verifySLSAProvenance} {
+		err := verify()
+		if err != nil {
+			u.fsys.Remove(u.tmpPath)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Apply performs the second half of [Updater.Update] (steps 5 to 8): the release downloaded by [Updater.Download] is installed in place of the current executable.
+func (u *Updater) Apply() error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return u.install()
+}
+
+func (u *Updater) install() error {
+	u.setPhase(PhaseInstalling)
+	err := u.apply()
+	if err != nil {
+		return u.fail(err)
+	}
+	u.setPhase(PhaseDone)
+
+	return nil
+}
+
+func (u *Updater) apply() error {
+	if u.staged == "" {
+		return fmt.Errorf("%w: no downloaded release to apply, call Download first", ErrInstallFailed)
+	}
+
+	defer u.cleanupDownload()
+
+	if _, err := u.fsys.Stat(u.staged); err != nil {
+		return fmt.Errorf("%w: downloaded release is gone -> %w", ErrInstallFailed, err)
+	}
+	u.tmpPath = u.staged
+
+	// an install is not interrupted once started, so make sure there is still time for it.
+	if err := u.ctx.Err(); err != nil {
+		u.fsys.Remove(u.tmpPath)
+		return fmt.Errorf("%w: update interrupted before install -> %w", ErrInstallFailed, err)
+	}
 
-	err = u.downloadAsset()
+	err := u.installNewRelease()
 	if err != nil {
 		return err
 	}
 
-	return u.installNewRelease()
+	u.logger.Info("update installed", "version", u.target.String(), "previous", u.Current.String())
+	u.Installed = u.target
+	u.Current = u.target
+
+	return nil
+}
+
+// cleanupDownload removes the download directory if it is empty: a partial download is kept to be resumed.
+func (u *Updater) cleanupDownload() {
+	u.staged = ""
+	u.memAsset = nil
+
+	u.fsys.Remove(u.tmpDir)
+}
+
+// UpdateToVersion will perform the update process (see [Updater.Update]) with the release of the given version instead of the latest one.
+// It works for both upgrade and downgrade. If no release is tagged with this version (`vX.Y.Z` or `X.Y.Z`), it returns [ErrReleaseNotFound].
+func (u *Updater) UpdateToVersion(v semver.Version) error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
+	rel, err := u.getReleaseByVersion(v)
+	if err != nil {
+		return err
+	}
+
+	err = u.loadManifest(rel)
+	if err != nil {
+		return err
+	}
+
+	u.assets = rel.Assets
+	u.target = v
+
+	return u.update()
 }
 
 // CheckAndUpdate will perform both the [Updater.CheckLatest] and [Updater.Update] actions.
 // It may seems a better solution for the developper as you don't have to do some plumbering but it enforce the user to update the application.
-func (u *Updater) CheckAndUpdate() error {
+// updated tells whether an update was installed, it is false when the current version is already the latest.
+func (u *Updater) CheckAndUpdate() (updated bool, err error) {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
 	isLatest, err := u.CheckLatest()
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if isLatest {
-		return nil
+		return false, nil
+	}
+
+	err = u.update()
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ForceUpdate will perform the update process (see [Updater.Update]) with the latest release, even if it isn't newer than the current version.
+// It re-installs a binary that got corrupted for instance: the downloaded asset is verified and the install rolled back on failure as usual.
+func (u *Updater) ForceUpdate() error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
 	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
+	rel, latest, err := u.latestRelease()
+	if err != nil {
+		return err
+	}
+
+	u.assets = rel.Assets
+	u.target = latest
+
+	return u.update()
+}
+
+// InstallOnly downloads the latest release and installs it at the target path (see [WithTargetPath]), without launching it.
+// Unlike [Updater.Update], meant for in-place self-updates, it is meant for bootstrap installs: the target may not exist yet,
+// and as the new binary is never launched, it is never rolled back because of a failing launch. On a first install, when the target doesn't exist yet,
+// the version check (see [WithVersionCheckCommand]) is skipped and a failing AfterSwap hook (see [WithHooks]) removes the new binary.
+func (u *Updater) InstallOnly() error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	defer u.withDeadline()()
+
+	rel, latest, err := u.latestRelease()
+	if err != nil {
+		return err
+	}
+
+	u.assets = rel.Assets
+	u.target = latest
+
+	err = u.download()
+	if err != nil {
+		return err
+	}
+
+	skipLaunch := u.skipLaunch
+	u.skipLaunch = true
+	defer func() {
+		u.skipLaunch = skipLaunch
+	}()
 
-	return u.Update()
+	return u.install()
 }
@@ -0,0 +1,205 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+// script is a fake binary exiting with code, tagged with version so that binaries can be told apart.
+func script(version string, code int) []byte {
+	return []byte(fmt.Sprintf("#!/bin/sh\n# %s\nexit %d\n", version, code))
+}
+
+// scriptUpdater updates the executable dir/repo, a script of version 1.0.0, to the release v1.1.0 whose binary is newBinary.
+// Scripts can be test launched on unix: the platform has no binary format to check.
+func scriptUpdater(t *testing.T, newBinary []byte, opts ...UpdaterOpts) (*Updater, string) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("scripts can't be launched on windows")
+	}
+
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", map[string][]byte{"repo_plan9_amd64": newBinary})
+
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "repo")
+	if err := os.WriteFile(exePath, script("1.0.0", 0), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append([]UpdaterOpts{WithProvider(provider), WithTargetPath(exePath), WithPlatform("plan9", "amd64"), WithForeignInstall(true),
+		WithRelaunchArgs([]string{})}, opts...)
+	return New("owner", "repo", semver.MustParse("1.0.0"), opts...), exePath
+}
+
+func checkFile(t *testing.T, path string, expected []byte) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != string(expected) {
+		t.Errorf("%s is %q, expected %q", path, content, expected)
+	}
+}
+
+func checkMissing(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("%s exists", path)
+	}
+}
+
+func TestKeepOldBinaryWithBackups(t *testing.T) {
+	u, exePath := scriptUpdater(t, script("1.1.0", 0), WithBackupRetention(1), WithKeepOldBinary(false))
+	for _, v := range []string{"0.8.0", "0.9.0"} {
+		if err := os.WriteFile(exePath+"-"+v+"-old", script(v, 0), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkFile(t, exePath, script("1.1.0", 0))
+	// the older backups are pruned, and the backup of the replaced version removed as requested.
+	for _, v := range []string{"0.8.0", "0.9.0", "1.0.0"} {
+		checkMissing(t, exePath+"-"+v+"-old")
+	}
+}
+
+func TestPruneBackupsWithoutLaunch(t *testing.T) {
+	u, exePath := scriptUpdater(t, script("1.1.0", 0), WithBackupRetention(1), WithLaunchVerification(false))
+	if err := os.WriteFile(exePath+"-0.9.0-old", script("0.9.0", 0), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// a backup that can't be removed doesn't fail the update.
+	if err := os.MkdirAll(filepath.Join(exePath+"-0.8.0-old", "busy"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	checkFile(t, exePath, script("1.1.0", 0))
+	checkFile(t, exePath+"-1.0.0-old", script("1.0.0", 0))
+	checkMissing(t, exePath+"-0.9.0-old")
+}
+
+func TestFailedLaunchRollsBack(t *testing.T) {
+	u, exePath := scriptUpdater(t, script("1.1.0", 1), WithRollbackVerification(true))
+
+	err := u.Update()
+	if !errors.Is(err, ErrInstallFailed) {
+		t.Fatalf("update of a broken binary returned %v, expected ErrInstallFailed", err)
+	}
+
+	checkFile(t, exePath, script("1.0.0", 0))
+	checkMissing(t, exePath+"-old")
+	if info, err := os.Stat(exePath); err != nil || info.Mode().Perm()&0111 == 0 {
+		t.Errorf("restored binary is not executable (%v)", err)
+	}
+}
+
+func TestRollbackWithoutOldBinary(t *testing.T) {
+	u, exePath := scriptUpdater(t, script("1.1.0", 0))
+	u.exePath = exePath
+
+	// the new binary is kept rather than nothing when the archived one is missing.
+	err := u.rollback()
+	if err == nil {
+		t.Fatal("rollback succeeded without an old binary")
+	}
+	checkFile(t, exePath, script("1.0.0", 0))
+}
+
+func TestUpdateOnItsOwn(t *testing.T) {
+	u, exePath := scriptUpdater(t, script("1.1.0", 0))
+	provider := u.provider.(*fakeProvider)
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, exePath, script("1.1.0", 0))
+	if status := u.Status(); status.Phase != PhaseDone {
+		t.Errorf("status is %s after an update", status.Phase)
+	}
+
+	// a second update resolves the latest release again rather than installing the same one.
+	provider.addRelease("v1.2.0", map[string][]byte{"repo_plan9_amd64": script("1.2.0", 0)})
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, exePath, script("1.2.0", 0))
+	if !u.Current.Equals(semver.MustParse("1.2.0")) {
+		t.Errorf("current version is %s after the second update", u.Current)
+	}
+
+	downloads := len(provider.downloads)
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if len(provider.downloads) != downloads {
+		t.Errorf("up to date binary updated again: %v", provider.downloads)
+	}
+	if status := u.Status(); status.Phase != PhaseIdle {
+		t.Errorf("status is %s when up to date", status.Phase)
+	}
+}
+
+func TestUpdateUpToDateAfterFailure(t *testing.T) {
+	provider := &fakeProvider{}
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithTargetPath(filepath.Join(t.TempDir(), "repo")))
+
+	if err := u.Update(); !errors.Is(err, ErrReleaseNotFound) {
+		t.Fatalf("update without release returned %v", err)
+	}
+	if status := u.Status(); status.Phase != PhaseFailed {
+		t.Errorf("status is %s after a failure", status.Phase)
+	}
+
+	provider.addRelease("v1.0.0", nil)
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if status := u.Status(); status.Phase != PhaseIdle || status.Err != nil {
+		t.Errorf("status is %s (%v) when up to date", status.Phase, status.Err)
+	}
+}
+
+func TestDownloadThenApply(t *testing.T) {
+	u, exePath := scriptUpdater(t, script("1.1.0", 0))
+
+	// no check for update beforehand, Download resolves the latest release on its own.
+	if err := u.Download(); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, exePath, script("1.0.0", 0))
+	if status := u.Status(); status.Phase != PhaseReady {
+		t.Errorf("status is %s after a download", status.Phase)
+	}
+
+	if err := u.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	checkFile(t, exePath, script("1.1.0", 0))
+
+	// up to date, there is nothing to apply.
+	if err := u.Download(); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.Apply(); !errors.Is(err, ErrInstallFailed) {
+		t.Errorf("expected ErrInstallFailed applying nothing, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package selfupdater
+
+import "regexp"
+
+// WithManagedBinary makes the [Updater] keep the binary at filePath current rather than the running executable, e.g. a helper binary
+// shipped along with the app: the asset downloaded is the one matching assetPattern (see [WithAssetPattern]) and the binary extracted
+// from an archive is the one named after filePath (unless set with [WithArchiveBinaryName]). The version given to [New] must be the one of this binary.
+// A pattern set with [WithAssetPattern] takes precedence over assetPattern, whatever the order of the options.
+// The test launch is disabled unless enabled with [WithLaunchVerification]: the binary is then launched without the arguments
+// of the current process, unless set with [WithRelaunchArgs].
+func WithManagedBinary(filePath string, assetPattern *regexp.Regexp) UpdaterOpts {
+	return func(u *Updater) {
+		u.targetPath = filePath
+		u.managedPattern = assetPattern
+		u.managed = true
+	}
+}
+
+// applyManagedDefaults resolves the defaults of [WithManagedBinary] once every option is applied, so that the options set explicitly
+// win whatever their order.
+func (u *Updater) applyManagedDefaults() {
+	if !u.managed {
+		return
+	}
+
+	if u.assetPattern == nil {
+		u.assetPattern = u.managedPattern
+	}
+	if !u.launchSet {
+		u.skipLaunch = true
+	}
+}
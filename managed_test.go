@@ -0,0 +1,29 @@
+package selfupdater
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+func TestManagedBinaryOptionOrder(t *testing.T) {
+	managed := regexp.MustCompile(`^helper_`)
+	explicit := regexp.MustCompile(`^helper-static_`)
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithManagedBinary("/opt/app/helper", managed))
+	if u.assetPattern != managed || !u.skipLaunch {
+		t.Errorf("managed defaults not applied: pattern %v, skip launch %t", u.assetPattern, u.skipLaunch)
+	}
+
+	options := []UpdaterOpts{WithManagedBinary("/opt/app/helper", managed), WithAssetPattern(explicit), WithLaunchVerification(true)}
+	reversed := slices.Clone(options)
+	slices.Reverse(reversed)
+	for _, opts := range [][]UpdaterOpts{options, reversed} {
+		u := New("owner", "repo", semver.MustParse("1.0.0"), opts...)
+		if u.assetPattern != explicit || u.skipLaunch {
+			t.Errorf("explicit options overridden: pattern %v, skip launch %t", u.assetPattern, u.skipLaunch)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package selfupdater
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// maxManifestSize bounds the manifest asset read with [WithManifest].
+const maxManifestSize = 1 << 20
+
+// Manifest is the JSON release asset read with [WithManifest], like:
+//
+//	{
+//	  "version": "1.2.3",
+//	  "platforms": {
+//	    "linux-amd64": {"asset": "myapp_linux_amd64.tar.gz", "sha256": "..."},
+//	    "darwin-arm64": {"url": "https://cdn.example.com/myapp_darwin_arm64.tar.gz", "sha256": "..."}
+//	  }
+//	}
+type Manifest struct {
+	// Version is the version of the release, a leading `v` is allowed.
+	Version string `json:"version"`
+	// Platforms are keyed by `os-arch`, with the GOOS and GOARCH values (`linux-armv7` for a 32-bit ARM version).
+	Platforms map[string]ManifestPlatform `json:"platforms"`
+}
+
+// ManifestPlatform is the binary of a [Manifest] for a platform.
+type ManifestPlatform struct {
+	// Asset is the name of the release asset to download.
+	Asset string `json:"asset"`
+	// URL is where to download the binary from when it is not a release asset, it is ignored if Asset is set.
+	URL string `json:"url"`
+	// SHA256 is the hex encoded checksum of the downloaded asset, it is not checked if empty.
+	SHA256 string `json:"sha256"`
+}
+
+// WithManifest makes the [Updater] read the version and the asset of each platform from the JSON manifest (see [Manifest])
+// published as the assetName asset of the release, rather than from the tag and the asset names. The version of the manifest
+// replaces the one of the tag, which still chooses the latest release. Checksum and signature verification still apply on top of the manifest checksum.
+// Without the manifest asset, the update fails with [ErrAssetNotFound].
+func WithManifest(assetName string) UpdaterOpts {
+	return func(u *Updater) {
+		u.manifestName = assetName
+	}
+}
+
+// loadManifest downloads and parses the manifest asset of rel into u.manifest, if enabled.
+func (u *Updater) loadManifest(rel *github.RepositoryRelease) error {
+	u.manifest = nil
+	if u.manifestName == "" {
+		return nil
+	}
+
+	var asset *github.ReleaseAsset
+	for _, ra := range rel.Assets {
+		if ra.GetName() == u.manifestName {
+			asset = ra
+			break
+		}
+	}
+	if asset == nil {
+		return fmt.Errorf("%w: no manifest %s in release %s", ErrAssetNotFound, u.manifestName, rel.GetTagName())
+	}
+
+	var manifest Manifest
+	err := u.retry(func() error {
+		reader, err := u.openAsset(asset.GetID())
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+
+		return json.NewDecoder(io.LimitReader(reader, maxManifestSize)).Decode(&manifest)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read manifest %s -> %w", u.manifestName, err)
+	}
+
+	if _, err := semver.ParseTolerant(manifest.Version); err != nil {
+		return fmt.Errorf("%w: invalid version %q in manifest %s -> %w", ErrReleaseNotFound, manifest.Version, u.manifestName, err)
+	}
+
+	u.manifest = &manifest
+
+	return nil
+}
+
+// manifestVersion returns the version of the loaded manifest.
+func (u *Updater) manifestVersion() semver.Version {
+	v, _ := semver.ParseTolerant(u.manifest.Version)
+	return v
+}
+
+// manifestPlatform returns the entry of the manifest for the current platform.
+func (u *Updater) manifestPlatform() (ManifestPlatform, bool) {
+	keys := []string{u.platform}
+	if u.goarch == "arm" {
+		keys = append([]string{fmt.Sprintf("%s-%s", u.goos, u.armPlatform())}, keys...)
+	}
+
+	for _, key := range keys {
+		if entry, ok := u.manifest.Platforms[key]; ok {
+			return entry, true
+		}
+	}
+
+	return ManifestPlatform{}, false
+}
+
+// manifestAsset returns the asset of the manifest for the current platform. An asset not part of the release has no ID, only a download URL.
+func (u *Updater) manifestAsset() (*github.ReleaseAsset, error) {
+	entry, ok := u.manifestPlatform()
+	if !ok {
+		return nil, fmt.Errorf("%w: no platform %s in manifest %s", ErrAssetNotFound, u.platform, u.manifestName)
+	}
+
+	if entry.Asset != "" {
+		for _, ra := range u.assets {
+			if ra.GetName() == entry.Asset {
+				return ra, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: no asset %s, listed in manifest %s; available: [%s]", ErrAssetNotFound, entry.Asset, u.manifestName, strings.Join(assetNames(u.assets), ", "))
+	}
+
+	parsed, err := url.Parse(entry.URL)
+	if err != nil || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: invalid url %q for platform %s in manifest %s", ErrAssetNotFound, entry.URL, u.platform, u.manifestName)
+	}
+
+	return &github.ReleaseAsset{Name: github.String(path.Base(parsed.Path)), BrowserDownloadURL: github.String(entry.URL)}, nil
+}
+
+// setAsset makes asset the one to download.
+func (u *Updater) setAsset(asset *github.ReleaseAsset) {
+	u.assetID = asset.GetID()
+	u.assetName = asset.GetName()
+	u.assetSize = int64(asset.GetSize())
+	u.assetURL = ""
+	if asset.GetID() == 0 {
+		u.assetURL = asset.GetBrowserDownloadURL()
+	}
+}
+
+// downloadURL streams the binary at rawURL, listed in the manifest or a public download URL (see [WithDirectDownload]).
+func (u *Updater) downloadURL(rawURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(u.ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if u.userAgent != "" {
+		req.Header.Set("User-Agent", u.userAgent)
+	}
+	for k, v := range u.downloadHeaders {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := github.CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return &responseBody{ReadCloser: resp.Body, header: resp.Header}, nil
+}
+
+// hasManifestChecksum tells whether the manifest has a checksum for the current platform.
+func (u *Updater) hasManifestChecksum() bool {
+	if u.manifest == nil {
+		return false
+	}
+	entry, ok := u.manifestPlatform()
+	return ok && entry.SHA256 != ""
+}
+
+// verifyManifestChecksum checks the downloaded asset against the checksum of the manifest.
+func (u *Updater) verifyManifestChecksum() error {
+	if u.manifest == nil {
+		return nil
+	}
+
+	entry, ok := u.manifestPlatform()
+	if !ok || entry.SHA256 == "" {
+		return nil
+	}
+
+	actual, err := u.downloadedHash(sha256.New())
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum of downloaded release asset -> %w", err)
+	}
+
+	if actual != strings.ToLower(entry.SHA256) {
+		return fmt.Errorf("%w: %s expected sha256 %s from manifest %s, got %s", ErrChecksumMismatch, u.assetName, entry.SHA256, u.manifestName, actual)
+	}
+
+	return nil
+}
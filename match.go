@@ -0,0 +1,278 @@
+package selfupdater
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// WithAssetSelector sets the function choosing the asset to download when several assets match the platform
+// (e.g. a regular, a debug and a static build). The first matching asset is used by default.
+// Returning nil makes the update fail with [ErrAssetNotFound].
+func WithAssetSelector(selector func(candidates []*github.ReleaseAsset) *github.ReleaseAsset) UpdaterOpts {
+	return func(u *Updater) {
+		u.assetSelector = selector
+	}
+}
+
+// WithContentType only keeps the matching assets with the given content type, like `application/octet-stream` for raw binaries
+// or `application/gzip` for archives, to tell apart a binary from a `.deb` or `.rpm` package built for the same platform.
+// Parameters like `; charset=` are ignored. If several assets of that content type match, the update fails with [ErrAssetNotFound]
+// listing them, unless one is chosen with [WithAssetSelector].
+func WithContentType(ct string) UpdaterOpts {
+	return func(u *Updater) {
+		u.contentType = ct
+	}
+}
+
+// WithAssetMatcher replaces the default platform matching of release assets: the first asset whose name satisfies match is downloaded.
+func WithAssetMatcher(match func(name string) bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.assetMatcher = match
+	}
+}
+
+// WithAssetPattern makes the [Updater] download the asset whose name matches re, for naming schemes embedding unpredictable parts
+// like a version or a commit hash (e.g. `^myapp-.*-linux-amd64\.tar\.gz$`). If several assets match, the update fails with [ErrAssetNotFound]
+// listing them, unless one is chosen with [WithAssetSelector]. Signatures, checksums and other auxiliary assets never match.
+func WithAssetPattern(re *regexp.Regexp) UpdaterOpts {
+	return func(u *Updater) {
+		u.assetPattern = re
+	}
+}
+
+// WithAssetFallbacks makes the [Updater] try the given platform tokens, in order, when no asset matches (e.g. `[]string{"linux_x86_64", "linux", "universal"}`),
+// for projects shipping a universal binary or naming their assets inconsistently across releases. An asset matches a token if its name contains every part
+// of it (split on `-`, `_` and `.` like asset names) and no os or arch token of another platform: `linux` matches `myapp-linux` or `myapp-linux-static`,
+// but not `myapp-linux-arm64` on amd64.
+func WithAssetFallbacks(tokens []string) UpdaterOpts {
+	return func(u *Updater) {
+		u.fallbacks = tokens
+	}
+}
+
+// fallbackCandidates returns the assets matching the first fallback token (see [WithAssetFallbacks]) some asset matches, along with the token.
+func (u *Updater) fallbackCandidates() ([]*github.ReleaseAsset, string) {
+	for _, token := range u.fallbacks {
+		parts := assetTokens(token)
+		if len(parts) == 0 {
+			continue
+		}
+
+		var candidates []*github.ReleaseAsset
+		for _, ra := range u.assets {
+			if isAuxiliaryAsset(ra.GetName()) {
+				continue
+			}
+
+			tokens := assetTokens(ra.GetName())
+			if u.foreignPlatform(tokens) {
+				continue
+			}
+			if !slices.ContainsFunc(parts, func(p string) bool { return !slices.Contains(tokens, p) }) {
+				candidates = append(candidates, ra)
+			}
+		}
+		if len(candidates) > 0 {
+			return candidates, token
+		}
+	}
+
+	return nil, ""
+}
+
+// foreignPlatform tells whether the asset tokens name another os or arch than the platform, like `darwin` or `arm64` on linux-amd64.
+func (u *Updater) foreignPlatform(tokens []string) bool {
+	if _, arm := assetARM(tokens); arm && u.goarch != "arm" {
+		return true
+	}
+
+	own := append(aliases(osAliases, u.goos), aliases(archAliases, u.goarch)...)
+	known := slices.Clone(elfSystems)
+	for _, table := range []map[string][]string{osAliases, archAliases} {
+		for _, a := range table {
+			known = append(known, a...)
+		}
+	}
+
+	return slices.ContainsFunc(tokens, func(t string) bool {
+		return slices.Contains(known, t) && !slices.Contains(own, t)
+	})
+}
+
+var osAliases = map[string][]string{
+	"darwin":  {"darwin", "macos", "osx"},
+	"windows": {"windows", "win"},
+}
+
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x86_64", "x64"},
+	"arm64": {"arm64", "aarch64"},
+	"386":   {"386", "i386", "i686", "x86"},
+}
+
+// auxiliaryExtensions are the extensions of assets that accompany a binary and must never be selected as the binary itself.
+var auxiliaryExtensions = []string{".sig", ".asc", ".sha256", ".sha512", ".minisig", ".pem", ".sbom", ".json", ".jsonl", ".bsdiff"}
+
+func aliases(table map[string][]string, value string) []string {
+	if a, ok := table[value]; ok {
+		return a
+	}
+	return []string{value}
+}
+
+func isAuxiliaryAsset(name string) bool {
+	lower := strings.ToLower(name)
+	return isChecksumAsset(name) || slices.ContainsFunc(auxiliaryExtensions, func(ext string) bool {
+		return strings.HasSuffix(lower, ext)
+	})
+}
+
+// assetTokens splits an asset name on the usual separators (`-`, `_`, `.`, space).
+// `x86_64` is rewritten beforehand so that it isn't split in two.
+func assetTokens(name string) []string {
+	name = strings.ToLower(name)
+	name = strings.NewReplacer("x86_64", "x86~64", "x86-64", "x86~64").Replace(name)
+
+	tokens := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ' '
+	})
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(t, "~", "_")
+	}
+
+	return tokens
+}
+
+// matchPlatform is the default asset matcher. It keeps the historic `os-arch` substring convention
+// and falls back on matching os and arch tokens, whatever the separator, order or alias used.
+// 32-bit ARM assets are matched on their version (`armv6`, `armv7`, ...), see [WithArch].
+// Assets built for another libc (`musl` or `gnu` in the name) than the system one are skipped, see [WithLibc].
+func (u *Updater) matchPlatform(name string) bool {
+	return u.platformMismatch(name) == ""
+}
+
+// platformMismatch tells why the asset doesn't match the platform (see [Updater.matchPlatform]), it returns an empty string if it does.
+func (u *Updater) platformMismatch(name string) string {
+	if isAuxiliaryAsset(name) {
+		return "checksum, signature or metadata file"
+	}
+
+	tokens := assetTokens(name)
+	if !u.matchLibc(tokens) {
+		return fmt.Sprintf("built for %s, not %s", assetLibc(tokens), u.libc)
+	}
+
+	// `linux-arm` is also a prefix of `linux-arm64` or `linux-armv7`, ARM versions are matched on tokens.
+	if u.goarch != "arm" && strings.Contains(name, u.platform) {
+		return ""
+	}
+
+	if u.goos == "windows" && slices.Contains(tokens, "exe") {
+		tokens = append(tokens, u.goos)
+	}
+
+	hasAny := func(candidates []string) bool {
+		return slices.ContainsFunc(candidates, func(c string) bool {
+			return slices.Contains(tokens, c)
+		})
+	}
+
+	if !hasAny(aliases(osAliases, u.goos)) {
+		return fmt.Sprintf("no %s os token", u.goos)
+	}
+
+	if u.goarch == "arm" {
+		if !u.matchARM(tokens) {
+			return fmt.Sprintf("no arm token compatible with %s", u.armPlatform())
+		}
+		return ""
+	}
+
+	if !hasAny(aliases(archAliases, u.goarch)) {
+		return fmt.Sprintf("no %s arch token", u.goarch)
+	}
+
+	return ""
+}
+
+// assetMatch returns the function matching the asset to download, along with a description of what it looks for.
+// A custom matcher has precedence over the asset pattern, then the asset template, then the platform matching.
+func (u *Updater) assetMatch() (func(name string) bool, string, error) {
+	switch {
+	case u.assetMatcher != nil:
+		return u.assetMatcher, "accepted by the asset matcher", nil
+	case u.assetPattern != nil:
+		// signatures and checksums of the asset often match the pattern too.
+		return func(name string) bool {
+			return !isAuxiliaryAsset(name) && u.assetPattern.MatchString(name)
+		}, fmt.Sprintf("matching /%s/", u.assetPattern), nil
+	case u.assetTmpl != nil:
+		names, err := u.templateNames()
+		if err != nil {
+			return nil, "", err
+		}
+		return func(name string) bool {
+			return slices.Contains(names, name)
+		}, fmt.Sprintf("named [%s]", strings.Join(names, ", ")), nil
+	default:
+		return u.matchPlatform, fmt.Sprintf("matching '%s'", u.platform), nil
+	}
+}
+
+func mediaType(ct string) string {
+	mt, _, _ := strings.Cut(ct, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
+
+// filterContentType keeps the candidates with the content type set by [WithContentType].
+func (u *Updater) filterContentType(candidates []*github.ReleaseAsset) ([]*github.ReleaseAsset, error) {
+	if u.contentType == "" {
+		return candidates, nil
+	}
+
+	filtered := slices.DeleteFunc(slices.Clone(candidates), func(ra *github.ReleaseAsset) bool {
+		return mediaType(ra.GetContentType()) != mediaType(u.contentType)
+	})
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("%w: no asset of content type %s among [%s]", ErrAssetNotFound, u.contentType, strings.Join(assetContentTypes(candidates), ", "))
+	}
+
+	return filtered, nil
+}
+
+// ambiguous tells whether the candidates left by a filter meant to single out the asset, like [WithContentType] or [WithAssetPattern],
+// can't be told apart. ARM versions (see [WithArch]) matched by the platform are not ambiguous, the best fitting one is ranked first.
+func (u *Updater) ambiguous(candidates []*github.ReleaseAsset) bool {
+	if len(candidates) < 2 {
+		return false
+	}
+	if u.assetPattern != nil {
+		return true
+	}
+	if u.contentType == "" {
+		return false
+	}
+
+	return u.goarch != "arm" || u.armRank(candidates[0]) == u.armRank(candidates[1])
+}
+
+// assetContentTypes describes the assets with their content type, like `myapp.deb (application/vnd.debian.binary-package)`.
+func assetContentTypes(assets []*github.ReleaseAsset) []string {
+	names := make([]string, 0, len(assets))
+	for _, ra := range assets {
+		names = append(names, fmt.Sprintf("%s (%s)", ra.GetName(), ra.GetContentType()))
+	}
+	return names
+}
+
+func assetNames(assets []*github.ReleaseAsset) []string {
+	names := make([]string, 0, len(assets))
+	for _, ra := range assets {
+		names = append(names, ra.GetName())
+	}
+	return names
+}
@@ -0,0 +1,150 @@
+package selfupdater
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+func TestMatchPlatformWindowsExe(t *testing.T) {
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("windows", "amd64"))
+
+	for name, expected := range map[string]bool{
+		"repo_windows_amd64.exe": true,
+		"repo_amd64.exe":         true,
+		"repo-x86_64.exe":        true,
+		"repo_windows_amd64.zip": true,
+		"repo_linux_amd64":       false,
+		"repo_arm64.exe":         false,
+	} {
+		if got := u.matchPlatform(name); got != expected {
+			t.Errorf("matchPlatform(%q) = %t, expected %t", name, got, expected)
+		}
+	}
+}
+
+func TestMatchPlatformExeOnLinux(t *testing.T) {
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", "amd64"))
+
+	if u.matchPlatform("repo_amd64.exe") {
+		t.Error("a windows executable matched on linux")
+	}
+}
+
+func typedAssets(assets map[string]string) []*github.ReleaseAsset {
+	var list []*github.ReleaseAsset
+	for name, ct := range assets {
+		list = append(list, &github.ReleaseAsset{Name: github.String(name), ContentType: github.String(ct)})
+	}
+	slices.SortFunc(list, func(a, b *github.ReleaseAsset) int { return strings.Compare(a.GetName(), b.GetName()) })
+	return list
+}
+
+func TestContentType(t *testing.T) {
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", "amd64"), WithContentType("application/octet-stream"))
+	u.assets = typedAssets(map[string]string{
+		"repo_linux_amd64":     "application/octet-stream",
+		"repo_linux_amd64.deb": "application/vnd.debian.binary-package",
+	})
+
+	asset, err := u.getAsset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.GetName() != "repo_linux_amd64" {
+		t.Errorf("selected asset is %s", asset.GetName())
+	}
+}
+
+func TestContentTypeAmbiguous(t *testing.T) {
+	assets := typedAssets(map[string]string{
+		"repo_linux_amd64":        "application/octet-stream",
+		"repo_linux_amd64_static": "application/octet-stream",
+		"repo_linux_amd64.deb":    "application/vnd.debian.binary-package",
+	})
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", "amd64"), WithContentType("application/octet-stream"))
+	u.assets = assets
+
+	_, err := u.getAsset()
+	if !errors.Is(err, ErrAssetNotFound) {
+		t.Fatalf("ambiguous assets returned %v", err)
+	}
+	for _, listed := range []string{"repo_linux_amd64 (application/octet-stream)", "repo_linux_amd64_static (application/octet-stream)"} {
+		if !strings.Contains(err.Error(), listed) {
+			t.Errorf("error doesn't list %s: %s", listed, err)
+		}
+	}
+
+	u = New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", "amd64"), WithContentType("application/octet-stream"),
+		WithAssetSelector(func(candidates []*github.ReleaseAsset) *github.ReleaseAsset { return candidates[1] }))
+	u.assets = assets
+
+	asset, err := u.getAsset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.GetName() != "repo_linux_amd64_static" {
+		t.Errorf("selected asset is %s", asset.GetName())
+	}
+}
+
+func TestAssetPatternAmbiguous(t *testing.T) {
+	assets := typedAssets(map[string]string{
+		"repo-1.1.0-abc123-linux-amd64.tar.gz":     "application/gzip",
+		"repo-1.1.0-def456-linux-amd64.tar.gz":     "application/gzip",
+		"repo-1.1.0-abc123-darwin-arm64.tar.gz":    "application/gzip",
+		"repo-1.1.0-abc123-linux-amd64.tar.gz.sig": "application/pgp-signature",
+	})
+	pattern := regexp.MustCompile(`^repo-.*-linux-amd64\.tar\.gz$`)
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithAssetPattern(pattern))
+	u.assets = assets
+
+	_, err := u.getAsset()
+	if !errors.Is(err, ErrAssetNotFound) {
+		t.Fatalf("several assets matching the pattern returned %v", err)
+	}
+	if !strings.Contains(err.Error(), "repo-1.1.0-def456-linux-amd64.tar.gz") {
+		t.Errorf("error doesn't list the matching assets: %s", err)
+	}
+
+	u = New("owner", "repo", semver.MustParse("1.0.0"), WithAssetPattern(regexp.MustCompile(`^repo-.*-abc123-linux-amd64\.tar\.gz$`)))
+	u.assets = assets
+
+	asset, err := u.getAsset()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset.GetName() != "repo-1.1.0-abc123-linux-amd64.tar.gz" {
+		t.Errorf("selected asset is %s", asset.GetName())
+	}
+}
+
+func TestFallbackCandidatesSkipForeignPlatforms(t *testing.T) {
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithPlatform("linux", "amd64"), WithAssetFallbacks([]string{"linux", "universal"}))
+	u.assets = typedAssets(map[string]string{
+		"repo-linux-arm64":       "",
+		"repo-linux-armv7":       "",
+		"repo-linux-i386":        "",
+		"repo-linux-static":      "",
+		"repo-darwin-universal":  "",
+		"repo-freebsd-universal": "",
+	})
+
+	candidates, token := u.fallbackCandidates()
+	if token != "linux" || !slices.Equal(assetNames(candidates), []string{"repo-linux-static"}) {
+		t.Errorf("fallback %q matched %v, expected repo-linux-static", token, assetNames(candidates))
+	}
+
+	u.assets = typedAssets(map[string]string{"repo-linux-arm64": "", "repo-darwin-universal": "", "repo-universal": ""})
+	candidates, token = u.fallbackCandidates()
+	if token != "universal" || !slices.Equal(assetNames(candidates), []string{"repo-universal"}) {
+		t.Errorf("fallback %q matched %v, expected repo-universal", token, assetNames(candidates))
+	}
+}
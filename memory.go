@@ -0,0 +1,103 @@
+package selfupdater
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// maxInMemorySize is the largest release asset downloaded with [WithInMemoryDownload].
+const maxInMemorySize = 512 << 20
+
+// WithInMemoryDownload makes the [Updater] download the release asset in memory rather than in a temp directory, for sandboxes where it can't be written.
+// The asset is verified and extracted in memory, then the binary is written next to the one it replaces, so that the only file written
+// is the new binary. The asset must be smaller than 512MiB. Delta updates (see [WithDeltaUpdates]), concurrent downloads (see [WithConcurrentDownload])
+// and download resumption don't apply.
+func WithInMemoryDownload(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.inMemory = enabled
+	}
+}
+
+// downloadToMemory downloads the release asset to u.memAsset.
+func (u *Updater) downloadToMemory() error {
+	if u.assetSize > maxInMemorySize {
+		return fmt.Errorf("%w: %s is %d bytes, more than the %d bytes of an in-memory download", ErrDownloadFailed, u.assetName, u.assetSize, maxInMemorySize)
+	}
+
+	// nothing is written to disk until the asset is verified.
+	u.tmpPath = ""
+
+	reader, err := u.openAsset(u.assetID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	u.logger.Info("downloading release asset in memory", "asset", u.assetName, "size", u.assetSize)
+
+	var buf bytes.Buffer
+	if u.assetSize > 0 {
+		buf.Grow(int(u.assetSize))
+	}
+
+	body, digest := newDigestCheck(reader)
+	progress := newProgressReader(&contextReader{ctx: u.ctx, reader: u.limitDownload(body, 0)}, u.assetSize, u.reportProgress)
+	// the size reported by the provider may be unknown (<= 0), one more byte tells that the limit is exceeded.
+	downloaded, err := io.Copy(&buf, io.LimitReader(progress, maxInMemorySize+1))
+	if errors.Is(err, ErrAssetTooLarge) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("%w: failed to read release asset -> %w", ErrDownloadFailed, err)
+	}
+
+	if downloaded > maxInMemorySize {
+		return fmt.Errorf("%w: %s is more than the %d bytes of an in-memory download", ErrDownloadFailed, u.assetName, maxInMemorySize)
+	}
+
+	if u.assetSize > 0 && downloaded != u.assetSize {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrIncompleteDownload, u.assetName, downloaded, u.assetSize)
+	}
+
+	err = digest.verify(u.assetName)
+	if err != nil {
+		return err
+	}
+
+	progress.done()
+	u.logger.Info("release asset downloaded", "asset", u.assetName, "bytes", downloaded)
+	u.memAsset = buf.Bytes()
+
+	return nil
+}
+
+// openDownloaded opens the downloaded release asset, from memory with [WithInMemoryDownload].
+func (u *Updater) openDownloaded() (io.ReadCloser, error) {
+	if u.memAsset != nil {
+		return &memoryAsset{bytes.NewReader(u.memAsset)}, nil
+	}
+
+	return u.fsys.Open(u.tmpPath)
+}
+
+// memoryAsset is the release asset downloaded in memory, with random access for zip archives.
+type memoryAsset struct {
+	*bytes.Reader
+}
+
+func (*memoryAsset) Close() error {
+	return nil
+}
+
+// memoryStagingPath is where the binary downloaded in memory is written, next to the binary it replaces.
+func (u *Updater) memoryStagingPath() (string, error) {
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(filepath.Dir(exePath), "."+filepath.Base(exePath)+".download"), nil
+}
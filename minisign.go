@@ -0,0 +1,75 @@
+package selfupdater
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jedisct1/go-minisign"
+)
+
+// WithMinisignPublicKey enables the verification of the minisign signature of the downloaded asset, expected in a `<asset name>.minisig` asset of the same release.
+// key is the minisign public key, either the base64 key alone (like `RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3`)
+// or the content of the `minisign.pub` file.
+func WithMinisignPublicKey(key string) UpdaterOpts {
+	return func(u *Updater) {
+		u.minisignKey = key
+	}
+}
+
+// parseMinisignKey reads the public key from its last non empty line, so that the untrusted comment of a `minisign.pub` file is skipped.
+func parseMinisignKey(key string) (minisign.PublicKey, error) {
+	lines := strings.Split(strings.TrimSpace(key), "\n")
+
+	return minisign.NewPublicKey(strings.TrimSpace(lines[len(lines)-1]))
+}
+
+func (u *Updater) verifyMinisignSignature() error {
+	if u.minisignKey == "" {
+		return nil
+	}
+
+	key, err := parseMinisignKey(u.minisignKey)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read minisign public key -> %w", ErrSignatureVerification, err)
+	}
+
+	asset := u.getSignatureAsset(".minisig")
+	if asset == nil {
+		return fmt.Errorf("%w: no minisign signature asset for %s", ErrSignatureVerification, u.assetName)
+	}
+
+	var sig []byte
+	err = u.retry(func() (err error) {
+		sig, err = u.fetchSignature(asset)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureVerification, err)
+	}
+
+	signature, err := minisign.DecodeSignature(string(sig))
+	if err != nil {
+		return fmt.Errorf("%w: malformed minisign signature %s -> %w", ErrSignatureVerification, asset.GetName(), err)
+	}
+
+	f, err := u.openDownloaded()
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded release asset -> %w", err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read downloaded release asset -> %w", err)
+	}
+
+	valid, err := key.Verify(data, signature)
+	if err != nil {
+		return fmt.Errorf("%w: %s -> %w", ErrSignatureVerification, asset.GetName(), err)
+	}
+	if !valid {
+		return fmt.Errorf("%w: invalid minisign signature %s for %s", ErrSignatureVerification, asset.GetName(), u.assetName)
+	}
+
+	return nil
+}
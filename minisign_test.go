@@ -0,0 +1,77 @@
+package selfupdater
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/blang/semver"
+)
+
+// minisignFixture signs with a fresh minisign key.
+type minisignFixture struct {
+	private ed25519.PrivateKey
+	public  string
+	keyID   []byte
+}
+
+func newMinisignFixture(t *testing.T) *minisignFixture {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyID := []byte("testkey!")
+	key := append(append([]byte("Ed"), keyID...), public...)
+	return &minisignFixture{private: private, public: base64.StdEncoding.EncodeToString(key), keyID: keyID}
+}
+
+// sign returns the content of the `.minisig` file of data.
+func (f *minisignFixture) sign(data []byte) []byte {
+	sig := ed25519.Sign(f.private, data)
+	global := ed25519.Sign(f.private, append(sig, "release"...))
+
+	encoded := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), f.keyID...), sig...))
+	return []byte("untrusted comment: test\n" + encoded + "\ntrusted comment: release\n" + base64.StdEncoding.EncodeToString(global) + "\n")
+}
+
+func minisignUpdate(t *testing.T, key string, asset, sig []byte) error {
+	t.Helper()
+
+	provider := &fakeProvider{}
+	provider.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": asset, "repo_linux_amd64.minisig": sig})
+
+	fsys := newMemFS()
+	fsys.files["app/repo"] = &fstest.MapFile{Data: elfBinary("v1"), Mode: 0755}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithFileSystem(fsys), WithTargetPath("/app/repo"),
+		WithPlatform("linux", "amd64"), WithLaunchVerification(false), WithMinisignPublicKey(key))
+	return u.Update()
+}
+
+func TestMinisignSignature(t *testing.T) {
+	fixture := newMinisignFixture(t)
+	asset := elfBinary("v2")
+
+	if err := minisignUpdate(t, fixture.public, asset, fixture.sign(asset)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMinisignInvalidSignature(t *testing.T) {
+	fixture := newMinisignFixture(t)
+	asset := elfBinary("v2")
+
+	err := minisignUpdate(t, fixture.public, asset, fixture.sign(elfBinary("tampered")))
+	if !errors.Is(err, ErrSignatureVerification) {
+		t.Fatalf("update with an invalid signature returned %v", err)
+	}
+	if strings.Contains(err.Error(), "%!") {
+		t.Errorf("malformed error message: %s", err)
+	}
+}
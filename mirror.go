@@ -0,0 +1,285 @@
+package selfupdater
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// RepoRef is a mirror of the repository, see [WithMirrors].
+type RepoRef struct {
+	Owner string
+	Repo  string
+	// BaseURL is the API URL of the GitHub Enterprise Server instance hosting the mirror (like [WithEnterpriseURL]), github.com if empty.
+	BaseURL string
+	// Token authenticates the calls to the mirror, the one given to [WithToken] is never sent to a mirror.
+	Token string
+	// Provider fetches the releases of the mirror from another source (e.g. [GitLabProvider]), the other fields are ignored if set.
+	Provider ReleaseProvider
+}
+
+// WithMirrors makes the [Updater] fall back to the given mirrors, in order, when the release lookup fails with a transient error
+// (network error, 5xx status, ...) on the primary repository. The asset is downloaded from the repository the release was found on,
+// or else from the next mirrors when that download fails with a transient error, the asset being looked up there by release tag and name.
+// Releases are compared and matched the same way on every mirror. A mirror with a malformed BaseURL makes every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithMirrors(mirrors []RepoRef) UpdaterOpts {
+	return func(u *Updater) {
+		u.mirrors = mirrors
+	}
+}
+
+// withMirrors returns a provider trying primary, then each mirror.
+func (u *Updater) withMirrors(primary ReleaseProvider) (ReleaseProvider, error) {
+	if len(u.mirrors) == 0 {
+		return primary, nil
+	}
+
+	providers := []ReleaseProvider{primary}
+	for _, m := range u.mirrors {
+		if m.Provider != nil {
+			providers = append(providers, m.Provider)
+			continue
+		}
+
+		client := github.NewClient(u.httpClient)
+		if m.Token != "" {
+			client = client.WithAuthToken(m.Token)
+		}
+		if u.userAgent != "" {
+			client.UserAgent = u.userAgent
+		}
+		if m.BaseURL != "" {
+			var err error
+			client, err = withEnterpriseURLs(client, m.BaseURL, "")
+			if err != nil {
+				return primary, fmt.Errorf("mirror %s/%s -> %w", m.Owner, m.Repo, err)
+			}
+		}
+
+		providers = append(providers, &githubProvider{client: client, httpClient: u.httpClient, owner: m.Owner, repo: m.Repo, headers: u.downloadHeaders})
+	}
+
+	return &mirrorProvider{providers: providers, logger: u.logger}, nil
+}
+
+// mirrorProvider is a [ReleaseProvider] failing over to the next provider on transient errors.
+// Assets are downloaded from the provider which returned their release, then from the next ones.
+type mirrorProvider struct {
+	providers []ReleaseProvider
+	logger    *slog.Logger
+
+	mu     sync.Mutex
+	active int
+	served map[int64]servedAsset
+}
+
+// servedAsset is an asset returned by one of the providers of a [mirrorProvider].
+type servedAsset struct {
+	provider int
+	tag      string
+	name     string
+}
+
+// failover calls fn with each provider until one succeeds or fails with a non transient error, and makes it the active one.
+func (m *mirrorProvider) failover(fn func(ReleaseProvider) error) error {
+	var err error
+	for i, p := range m.providers {
+		err = fn(p)
+		if err == nil {
+			m.mu.Lock()
+			m.active = i
+			m.mu.Unlock()
+			return nil
+		}
+
+		if !isTransient(err) {
+			return err
+		}
+		if i < len(m.providers)-1 {
+			m.logger.Warn("release lookup failed, trying the next mirror", "mirror", i+1, "error", err)
+		}
+	}
+
+	return err
+}
+
+func (m *mirrorProvider) activeProvider() ReleaseProvider {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.providers[m.active]
+}
+
+func (m *mirrorProvider) activeIndex() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.active
+}
+
+// record remembers that the assets of releases are served by the provider at index, so that they are downloaded from it.
+func (m *mirrorProvider) record(index int, releases ...*github.RepositoryRelease) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.served == nil {
+		m.served = make(map[int64]servedAsset)
+	}
+	for _, rel := range releases {
+		for _, asset := range rel.Assets {
+			m.served[asset.GetID()] = servedAsset{provider: index, tag: rel.GetTagName(), name: asset.GetName()}
+		}
+	}
+}
+
+// download calls fn with the provider serving the asset id, then on transient errors with the next providers,
+// with the ID of the asset of the same name in their release of the same tag.
+func (m *mirrorProvider) download(ctx context.Context, id int64, fn func(p ReleaseProvider, id int64, first bool) error) error {
+	m.mu.Lock()
+	asset, ok := m.served[id]
+	if !ok {
+		asset.provider = m.active
+	}
+	m.mu.Unlock()
+
+	err := fn(m.providers[asset.provider], id, true)
+	if err == nil || !ok || !isTransient(err) {
+		return err
+	}
+
+	for i := 1; i < len(m.providers); i++ {
+		index := (asset.provider + i) % len(m.providers)
+		m.logger.Warn("release asset download failed, trying the next mirror", "asset", asset.name, "mirror", index, "error", err)
+
+		p := m.providers[index]
+		rel, errRel := p.ReleaseByTag(ctx, asset.tag)
+		if errRel != nil {
+			m.logger.Debug("failed to find the release on the mirror", "mirror", index, "tag", asset.tag, "error", errRel)
+			continue
+		}
+
+		idx := slices.IndexFunc(rel.Assets, func(ra *github.ReleaseAsset) bool {
+			return ra.GetName() == asset.name
+		})
+		if idx == -1 {
+			m.logger.Debug("no such release asset on the mirror", "mirror", index, "tag", asset.tag, "asset", asset.name)
+			continue
+		}
+
+		err = fn(p, rel.Assets[idx].GetID(), false)
+		if err == nil || !isTransient(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (m *mirrorProvider) LatestRelease(ctx context.Context) (*github.RepositoryRelease, error) {
+	var rel *github.RepositoryRelease
+	err := m.failover(func(p ReleaseProvider) error {
+		var err error
+		rel, err = p.LatestRelease(ctx)
+		return err
+	})
+	if err == nil {
+		m.record(m.activeIndex(), rel)
+	}
+
+	return rel, err
+}
+
+func (m *mirrorProvider) ReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	var rel *github.RepositoryRelease
+	err := m.failover(func(p ReleaseProvider) error {
+		var err error
+		rel, err = p.ReleaseByTag(ctx, tag)
+		return err
+	})
+	if err == nil {
+		m.record(m.activeIndex(), rel)
+	}
+
+	return rel, err
+}
+
+// ListReleases fails over on the first page only, the next ones come from the provider which returned it.
+func (m *mirrorProvider) ListReleases(ctx context.Context, page int) ([]*github.RepositoryRelease, int, error) {
+	if page > 0 {
+		releases, next, err := m.activeProvider().ListReleases(ctx, page)
+		if err == nil {
+			m.record(m.activeIndex(), releases...)
+		}
+		return releases, next, err
+	}
+
+	var (
+		releases []*github.RepositoryRelease
+		next     int
+	)
+	err := m.failover(func(p ReleaseProvider) error {
+		var err error
+		releases, next, err = p.ListReleases(ctx, page)
+		return err
+	})
+	if err == nil {
+		m.record(m.activeIndex(), releases...)
+	}
+
+	return releases, next, err
+}
+
+func (m *mirrorProvider) DownloadAsset(ctx context.Context, id int64) (io.ReadCloser, error) {
+	var reader io.ReadCloser
+	err := m.download(ctx, id, func(p ReleaseProvider, id int64, _ bool) error {
+		var err error
+		reader, err = p.DownloadAsset(ctx, id)
+		return err
+	})
+
+	return reader, err
+}
+
+// DownloadAssetFrom only resumes the download on the provider serving the asset, the copy of another one is downloaded from the beginning.
+func (m *mirrorProvider) DownloadAssetFrom(ctx context.Context, id int64, offset int64) (io.ReadCloser, bool, error) {
+	var (
+		reader  io.ReadCloser
+		resumed bool
+	)
+	err := m.download(ctx, id, func(p ReleaseProvider, id int64, first bool) error {
+		var err error
+		if rd, ok := p.(rangeDownloader); ok && first {
+			reader, resumed, err = rd.DownloadAssetFrom(ctx, id, offset)
+			return err
+		}
+		reader, resumed = nil, false
+		reader, err = p.DownloadAsset(ctx, id)
+		return err
+	})
+
+	return reader, resumed, err
+}
+
+func (m *mirrorProvider) DownloadAssetRange(ctx context.Context, id int64, start, end int64) (io.ReadCloser, bool, error) {
+	var (
+		reader io.ReadCloser
+		ranged bool
+	)
+	err := m.download(ctx, id, func(p ReleaseProvider, id int64, _ bool) error {
+		var err error
+		if pd, ok := p.(partDownloader); ok {
+			reader, ranged, err = pd.DownloadAssetRange(ctx, id, start, end)
+			return err
+		}
+		reader, ranged = nil, false
+		reader, err = p.DownloadAsset(ctx, id)
+		return err
+	})
+
+	return reader, ranged, err
+}
@@ -0,0 +1,92 @@
+package selfupdater
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// flakyProvider is a [fakeProvider] whose calls fail with a transient error when told to.
+type flakyProvider struct {
+	*fakeProvider
+	failLookups   bool
+	failDownloads bool
+}
+
+func (p *flakyProvider) LatestRelease(ctx context.Context) (*github.RepositoryRelease, error) {
+	if p.failLookups {
+		return nil, fmt.Errorf("lookup -> %w", io.ErrUnexpectedEOF)
+	}
+	return p.fakeProvider.LatestRelease(ctx)
+}
+
+func (p *flakyProvider) DownloadAsset(ctx context.Context, id int64) (io.ReadCloser, error) {
+	if p.failDownloads {
+		return nil, fmt.Errorf("download -> %w", io.ErrUnexpectedEOF)
+	}
+	return p.fakeProvider.DownloadAsset(ctx, id)
+}
+
+// mirrorRelease publishes the release v1.1.0 on a mirror, with a binary of the same size as the primary one, after another release so that its asset IDs differ from the primary ones.
+func mirrorRelease(binary []byte) *fakeProvider {
+	mirror := &fakeProvider{}
+	mirror.addRelease("v1.0.0", map[string][]byte{"repo_linux_amd64.tar.gz": nil, "checksums.txt": nil})
+	mirror.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": binary})
+	return mirror
+}
+
+func mirrorUpdate(t *testing.T, repo string, primary *flakyProvider, mirror *fakeProvider, opts ...UpdaterOpts) (*memFS, error) {
+	t.Helper()
+
+	fsys := newMemFS()
+	fsys.files["app/repo"] = &fstest.MapFile{Data: elfBinary("v1"), Mode: 0755}
+
+	opts = append([]UpdaterOpts{WithProvider(primary), WithMirrors([]RepoRef{{Provider: mirror}}), WithFileSystem(fsys), WithTargetPath("/app/repo"),
+		WithPlatform("linux", "amd64"), WithLaunchVerification(false)}, opts...)
+	u := New("owner", repo, semver.MustParse("1.0.0"), opts...)
+
+	return fsys, u.Update()
+}
+
+func TestMirrorDownloadFailover(t *testing.T) {
+	primary := &flakyProvider{fakeProvider: &fakeProvider{}, failDownloads: true}
+	primary.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": elfBinary("primary")})
+	mirror := mirrorRelease(elfBinary("mirror!"))
+
+	fsys, err := mirrorUpdate(t, "repo", primary, mirror)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/repo"); got != string(elfBinary("mirror!")) {
+		t.Errorf("installed binary is %q", got)
+	}
+}
+
+func TestMirrorCachedRelease(t *testing.T) {
+	// the release is found on the mirror and cached.
+	primary := &flakyProvider{fakeProvider: &fakeProvider{}, failLookups: true}
+	primary.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": elfBinary("primary")})
+	mirror := mirrorRelease(elfBinary("mirror!"))
+
+	if _, err := mirrorUpdate(t, "mirror-cache", primary, mirror, WithCacheTTL(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the cached release assets are downloaded from the mirror, even once the primary repository is back.
+	primary.failLookups = false
+	fsys, err := mirrorUpdate(t, "mirror-cache", primary, mirror, WithCacheTTL(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fsys.content(t, "/app/repo"); got != string(elfBinary("mirror!")) {
+		t.Errorf("installed binary is %q", got)
+	}
+}
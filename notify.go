@@ -0,0 +1,24 @@
+package selfupdater
+
+// UpdateNotification is the result of [Updater.NotifyInfo].
+type UpdateNotification struct {
+	UpdateAvailable
+	// DownloadURL is the browser download URL of the asset matching the platform, empty if none matches.
+	DownloadURL string
+}
+
+// NotifyInfo tells whether the latest release is newer than the current version, with the links to let the user update manually:
+// the release page and the download URL of the asset for the platform. Like [Updater.CheckForUpdate], nothing is downloaded nor written to disk.
+func (u *Updater) NotifyInfo() (*UpdateNotification, error) {
+	available, err := u.CheckForUpdate()
+	if err != nil {
+		return nil, err
+	}
+
+	notification := &UpdateNotification{UpdateAvailable: *available}
+	if asset, err := u.getAsset(); err == nil {
+		notification.DownloadURL = asset.GetBrowserDownloadURL()
+	}
+
+	return notification, nil
+}
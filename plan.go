@@ -0,0 +1,54 @@
+package selfupdater
+
+import "github.com/blang/semver"
+
+// UpdatePlan describes what [Updater.Update] would install.
+type UpdatePlan struct {
+	Current   semver.Version
+	Target    semver.Version
+	AssetName string
+	AssetSize int64
+	ExePath   string
+}
+
+// DryRun resolves the latest release and the asset matching the current platform, without downloading or replacing anything.
+// It lets you show the user what would be installed before calling [Updater.Update].
+// It fails with [ErrNotWritable] if the update couldn't be installed for lack of permissions.
+func (u *Updater) DryRun() (*UpdatePlan, error) {
+	defer u.withDeadline()()
+
+	rel, latest, err := u.latestRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	u.assets = rel.Assets
+	u.target = latest
+
+	return u.plan()
+}
+
+func (u *Updater) plan() (*UpdatePlan, error) {
+	asset, err := u.getAsset()
+	if err != nil {
+		return nil, err
+	}
+
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.checkWritable(exePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdatePlan{
+		Current:   u.Current,
+		Target:    u.target,
+		AssetName: asset.GetName(),
+		AssetSize: int64(asset.GetSize()),
+		ExePath:   exePath,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package selfupdater
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// WithPlatform makes the [Updater] match the assets built for goos and arch (like `darwin` and `arm64`, see [WithArch] for ARM versions)
+// instead of the current platform, to fetch them with [Updater.DownloadTo] from a CI runner for instance. Set [WithLibc] after it if needed,
+// the detected libc doesn't apply to another OS. Installing a binary built for another platform than the current one fails with
+// [ErrInvalidBinary], unless forced with [WithForeignInstall].
+func WithPlatform(goos, arch string) UpdaterOpts {
+	return func(u *Updater) {
+		if goos != runtime.GOOS {
+			u.libc = ""
+		}
+		u.goos = goos
+		WithArch(arch)(u)
+		u.crossPlatform = goos != runtime.GOOS || u.goarch != runtime.GOARCH
+	}
+}
+
+// WithForeignInstall allows installing the binary of the platform set with [WithPlatform] in place of the current executable,
+// even though it is not built for the current platform, like an `amd64` binary run through Rosetta on an `arm64` mac.
+func WithForeignInstall(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.foreignInstall = enabled
+	}
+}
+
+// checkPlatform refuses to install a binary built for another platform than the current one, see [WithPlatform].
+func (u *Updater) checkPlatform() error {
+	if !u.crossPlatform || u.foreignInstall {
+		return nil
+	}
+
+	return fmt.Errorf("%w: refusing to install a %s binary on %s-%s, see WithForeignInstall", ErrInvalidBinary, u.platform, runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,119 @@
+package selfupdater
+
+import (
+	"io"
+	"time"
+)
+
+// WithProgress will make the [Updater] call fn while downloading the release asset.
+// total is the asset size reported by github, or -1 if it is unknown. fn is called one last time once the download is complete.
+func WithProgress(fn func(downloaded, total int64)) UpdaterOpts {
+	return func(u *Updater) {
+		u.progress = fn
+	}
+}
+
+// Progress describes the progress of a download, see [WithDetailedProgress].
+type Progress struct {
+	Downloaded int64
+	// Total is the asset size, or -1 if it is unknown.
+	Total int64
+	// BytesPerSec is the download speed, averaged over the last seconds.
+	BytesPerSec float64
+	// ETA is the estimated time remaining, 0 if it is unknown.
+	ETA time.Duration
+}
+
+// WithDetailedProgress will make the [Updater] call fn while downloading the release asset, with the download speed and the estimated time remaining.
+// fn is called one last time once the download is complete.
+func WithDetailedProgress(fn func(p Progress)) UpdaterOpts {
+	return func(u *Updater) {
+		u.detailedProgress = fn
+	}
+}
+
+const (
+	// rateSampleInterval is the minimum delay between two speed samples, so that bursty reads don't make the speed jitter.
+	rateSampleInterval = 250 * time.Millisecond
+	// rateSmoothing is the weight of the last sample in the moving average of the speed.
+	rateSmoothing = 0.3
+)
+
+// rateMeter computes the exponential moving average of a download speed.
+type rateMeter struct {
+	last      time.Time
+	lastBytes int64
+	rate      float64
+}
+
+func (m *rateMeter) update(downloaded int64, now time.Time) float64 {
+	if m.last.IsZero() {
+		m.last, m.lastBytes = now, downloaded
+		return m.rate
+	}
+
+	elapsed := now.Sub(m.last)
+	if elapsed < rateSampleInterval {
+		return m.rate
+	}
+
+	sample := float64(downloaded-m.lastBytes) / elapsed.Seconds()
+	if m.rate == 0 {
+		m.rate = sample
+	} else {
+		m.rate = rateSmoothing*sample + (1-rateSmoothing)*m.rate
+	}
+	m.last, m.lastBytes = now, downloaded
+
+	return m.rate
+}
+
+func newProgress(downloaded, total int64, rate float64) Progress {
+	p := Progress{Downloaded: downloaded, Total: total, BytesPerSec: rate}
+	if rate > 0 && total > downloaded {
+		p.ETA = time.Duration(float64(total-downloaded) / rate * float64(time.Second))
+	}
+
+	return p
+}
+
+// progressReader counts the bytes read from the underlying reader and reports them on each read.
+type progressReader struct {
+	reader     io.Reader
+	downloaded int64
+	total      int64
+	onProgress func(downloaded, total int64)
+}
+
+func newProgressReader(reader io.Reader, size int64, onProgress func(downloaded, total int64)) *progressReader {
+	total := size
+	if total <= 0 {
+		total = -1
+	}
+
+	return &progressReader{
+		reader:     reader,
+		total:      total,
+		onProgress: onProgress,
+	}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if n > 0 {
+		p.downloaded += int64(n)
+		p.onProgress(p.downloaded, p.total)
+	}
+
+	return n, err
+}
+
+// done reports the download as complete. When the total is unknown, the downloaded size is used as total.
+func (p *progressReader) done() {
+	total := p.total
+	if total == -1 {
+		total = p.downloaded
+	}
+
+	p.onProgress(p.downloaded, total)
+}
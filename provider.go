@@ -0,0 +1,226 @@
+package selfupdater
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// ReleaseProvider is the source of the releases an [Updater] updates from. Github is used by default, see [WithProvider] to use another one.
+// Whatever the provider, releases and assets are described with the go-github types so that the [Updater] logic
+// (asset matching, download, verification, install and rollback) stays the same.
+type ReleaseProvider interface {
+	// LatestRelease returns the latest (non prerelease) release.
+	LatestRelease(ctx context.Context) (*github.RepositoryRelease, error)
+	// ReleaseByTag returns the release with the given tag, or an error wrapping [ErrReleaseNotFound] if there is none.
+	ReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error)
+	// ListReleases returns a page of releases (the first one for page 0) and the next page number, 0 being the last page.
+	ListReleases(ctx context.Context, page int) ([]*github.RepositoryRelease, int, error)
+	// DownloadAsset streams the asset with the given ID.
+	DownloadAsset(ctx context.Context, id int64) (io.ReadCloser, error)
+}
+
+// rangeDownloader is implemented by providers able to resume a download.
+type rangeDownloader interface {
+	// DownloadAssetFrom streams the asset from offset, it tells whether the offset was honored or the asset is streamed from the beginning.
+	DownloadAssetFrom(ctx context.Context, id int64, offset int64) (io.ReadCloser, bool, error)
+}
+
+// releaseRecorder is implemented by providers which can only download the assets of releases they returned, so that a release served
+// from the cache (see [WithCacheTTL]) can be handed over to them.
+type releaseRecorder interface {
+	recordRelease(rel *github.RepositoryRelease)
+}
+
+// WithProvider makes the [Updater] fetch releases from provider instead of github (e.g. [GitLabProvider]).
+// Github specific options (WithToken, WithEnterpriseURL, ...) don't apply to it.
+func WithProvider(provider ReleaseProvider) UpdaterOpts {
+	return func(u *Updater) {
+		u.provider = provider
+	}
+}
+
+// githubProvider is the default [ReleaseProvider], based on github releases.
+type githubProvider struct {
+	client     *github.Client
+	httpClient *http.Client
+	owner      string
+	repo       string
+	// headers are added to the requests made to the storage URL, see [WithDownloadHeaders].
+	headers map[string]string
+}
+
+func (g *githubProvider) LatestRelease(ctx context.Context) (*github.RepositoryRelease, error) {
+	rel, _, err := g.client.Repositories.GetLatestRelease(ctx, g.owner, g.repo)
+	return rel, err
+}
+
+func (g *githubProvider) ReleaseByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	rel, resp, err := g.client.Repositories.GetReleaseByTag(ctx, g.owner, g.repo, tag)
+	if err != nil && resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: no release tagged %s", ErrReleaseNotFound, tag)
+	}
+
+	return rel, err
+}
+
+func (g *githubProvider) ListReleases(ctx context.Context, page int) ([]*github.RepositoryRelease, int, error) {
+	releases, resp, err := g.client.Repositories.ListReleases(ctx, g.owner, g.repo, &github.ListOptions{Page: page, PerPage: 100})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return releases, resp.NextPage, nil
+}
+
+// DownloadAsset streams the given release asset.
+// The github API call is authenticated (if a token is set) while the redirect to the storage URL is followed with the bare http client
+// as it is already signed and would be rejected if it carried the Authorization header.
+func (g *githubProvider) DownloadAsset(ctx context.Context, id int64) (io.ReadCloser, error) {
+	reader, redirect, err := g.requestAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if redirect == "" {
+		return reader, nil
+	}
+
+	resp, err := g.getRedirect(ctx, redirect, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow redirect url -> %w", err)
+	}
+
+	if err := github.CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to follow redirect url -> %w", err)
+	}
+
+	return &responseBody{ReadCloser: resp.Body, header: resp.Header}, nil
+}
+
+// requestAsset makes the API request of an asset download like [github.RepositoriesService.DownloadReleaseAsset], with the download headers.
+// It returns the asset when the API serves it itself, or else the storage URL it redirects to.
+func (g *githubProvider) requestAsset(ctx context.Context, id int64) (io.ReadCloser, string, error) {
+	req, err := g.client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/releases/assets/%d", g.owner, g.repo, id), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	for k, v := range g.headers {
+		req.Header.Set(k, v)
+	}
+
+	// the copy of the http client authenticates the request, the redirect is followed by getRedirect.
+	var redirect string
+	client := g.client.Client()
+	client.CheckRedirect = func(req *http.Request, _ []*http.Request) error {
+		redirect = req.URL.String()
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, "", err
+	}
+	if redirect != "" {
+		resp.Body.Close()
+		return nil, redirect, nil
+	}
+
+	if err := github.CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, "", err
+	}
+
+	return resp.Body, "", nil
+}
+
+// getRedirect requests the storage URL an asset download is redirected to, restricted to byteRange if it is not empty.
+func (g *githubProvider) getRedirect(ctx context.Context, redirect string, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, redirect, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build redirect request -> %w", err)
+	}
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("User-Agent", g.client.UserAgent)
+	for k, v := range g.headers {
+		req.Header.Set(k, v)
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+
+	return g.httpClient.Do(req)
+}
+
+// DownloadAssetFrom streams the given release asset starting at offset, using an HTTP Range request on the storage URL.
+func (g *githubProvider) DownloadAssetFrom(ctx context.Context, id int64, offset int64) (io.ReadCloser, bool, error) {
+	reader, redirect, err := g.requestAsset(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// served by the API itself, without ranges support.
+	if redirect == "" {
+		return reader, false, nil
+	}
+
+	resp, err := g.getRedirect(ctx, redirect, fmt.Sprintf("bytes=%d-", offset))
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return resp.Body, true, nil
+	case http.StatusOK:
+		return &responseBody{ReadCloser: resp.Body, header: resp.Header}, false, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		reader, err := g.DownloadAsset(ctx, id)
+		return reader, false, err
+	}
+
+	err = github.CheckResponse(resp)
+	resp.Body.Close()
+	return nil, false, err
+}
+
+// DownloadAssetRange streams the bytes start to end (inclusive) of the given release asset, using an HTTP Range request on the storage URL.
+// It tells whether the range was honored or the whole asset is streamed.
+func (g *githubProvider) DownloadAssetRange(ctx context.Context, id int64, start, end int64) (io.ReadCloser, bool, error) {
+	reader, redirect, err := g.requestAsset(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if redirect == "" {
+		return reader, false, nil
+	}
+
+	resp, err := g.getRedirect(ctx, redirect, fmt.Sprintf("bytes=%d-%d", start, end))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := github.CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, false, err
+	}
+
+	return resp.Body, resp.StatusCode == http.StatusPartialContent, nil
+}
+
+// WithDownloadHeaders adds the given headers to every asset download (ranged and resumed downloads included), for storages with their own
+// access control like a bucket token: the API request of the download, the storage URL github redirects to, and the downloads from a URL
+// (see [WithDirectDownload] and [WithManifest]). The other API calls, authenticated with [WithToken], don't get them.
+// Apart from downloads from a URL, it only applies to the default github provider.
+func WithDownloadHeaders(headers map[string]string) UpdaterOpts {
+	return func(u *Updater) {
+		u.downloadHeaders = headers
+	}
+}
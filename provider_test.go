@@ -0,0 +1,177 @@
+package selfupdater
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// fakeProvider serves the given releases, whose assets content is keyed by asset ID.
+type fakeProvider struct {
+	releases  []*github.RepositoryRelease
+	content   map[int64][]byte
+	downloads []string
+}
+
+// addRelease publishes a release tagged tag with the given assets content, keyed by name.
+func (p *fakeProvider) addRelease(tag string, assets map[string][]byte) *github.RepositoryRelease {
+	if p.content == nil {
+		p.content = make(map[int64][]byte)
+	}
+
+	rel := &github.RepositoryRelease{TagName: github.String(tag)}
+	for name, content := range assets {
+		id := int64(len(p.content) + 1)
+		p.content[id] = content
+		rel.Assets = append(rel.Assets, &github.ReleaseAsset{
+			ID:   github.Int64(id),
+			Name: github.String(name),
+			Size: github.Int(len(content)),
+		})
+	}
+	p.releases = append([]*github.RepositoryRelease{rel}, p.releases...)
+
+	return rel
+}
+
+func (p *fakeProvider) LatestRelease(context.Context) (*github.RepositoryRelease, error) {
+	if len(p.releases) == 0 {
+		return nil, fmt.Errorf("%w: no release", ErrReleaseNotFound)
+	}
+	return p.releases[0], nil
+}
+
+func (p *fakeProvider) ReleaseByTag(_ context.Context, tag string) (*github.RepositoryRelease, error) {
+	for _, rel := range p.releases {
+		if rel.GetTagName() == tag {
+			return rel, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrReleaseNotFound, tag)
+}
+
+func (p *fakeProvider) ListReleases(context.Context, int) ([]*github.RepositoryRelease, int, error) {
+	return p.releases, 0, nil
+}
+
+func (p *fakeProvider) DownloadAsset(_ context.Context, id int64) (io.ReadCloser, error) {
+	content, ok := p.content[id]
+	if !ok {
+		return nil, fmt.Errorf("no asset %d", id)
+	}
+	p.downloads = append(p.downloads, p.assetName(id))
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (p *fakeProvider) assetName(id int64) string {
+	for _, rel := range p.releases {
+		for _, asset := range rel.Assets {
+			if asset.GetID() == id {
+				return asset.GetName()
+			}
+		}
+	}
+	return ""
+}
+
+// tarGz builds a tar.gz archive holding a single file.
+func tarGz(t *testing.T, name string, content []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := errors.Join(tw.Close(), gz.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// headersServer serves an asset through the github API, by itself or redirecting to a storage URL, and records the X-Bucket header of each request.
+func headersServer(t *testing.T, redirect bool) (*httptest.Server, map[string]string) {
+	t.Helper()
+
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]string)
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path] = r.Header.Get("X-Bucket")
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/repos/owner/repo/releases/assets/1" && redirect:
+			http.Redirect(w, r, "/storage/asset", http.StatusFound)
+		default:
+			w.Write([]byte("asset"))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, seen
+}
+
+func TestDownloadHeaders(t *testing.T) {
+	for _, redirect := range []bool{false, true} {
+		srv, seen := headersServer(t, redirect)
+
+		client := github.NewClient(srv.Client())
+		client.BaseURL, _ = url.Parse(srv.URL + "/")
+		provider := &githubProvider{client: client, httpClient: srv.Client(), owner: "owner", repo: "repo", headers: map[string]string{"X-Bucket": "token"}}
+
+		reader, err := provider.DownloadAsset(context.Background(), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil || string(content) != "asset" {
+			t.Fatalf("downloaded %q (%v)", content, err)
+		}
+
+		paths := []string{"/repos/owner/repo/releases/assets/1"}
+		if redirect {
+			paths = append(paths, "/storage/asset")
+		}
+		for _, path := range paths {
+			if seen[path] != "token" {
+				t.Errorf("request to %s (redirect %t) is missing the download header", path, redirect)
+			}
+		}
+	}
+}
+
+func TestDownloadHeadersFromURL(t *testing.T) {
+	srv, seen := headersServer(t, false)
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithHttpClient(srv.Client()), WithDownloadHeaders(map[string]string{"X-Bucket": "token"}))
+	reader, err := u.downloadURL(srv.URL + "/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader.Close()
+
+	if seen["/manifest.json"] != "token" {
+		t.Error("download from a URL is missing the download header")
+	}
+}
@@ -0,0 +1,57 @@
+package selfupdater
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// WithProxy routes both the API calls and the asset downloads (including the redirects to the storage URL) of the [Updater] through the given proxy,
+// like `http://proxy.corp:3128` (`http`, `https` and `socks5` schemes are supported).
+// Without it, the proxy environment variables (HTTPS_PROXY, HTTP_PROXY, NO_PROXY) are honored as long as the http client uses the default transport.
+// The proxy is set on a copy of the client given with [WithHttpClient], whose transport must then be an *http.Transport.
+// The client of another [ReleaseProvider] (e.g. [GitLabProvider]) is left untouched. An invalid proxy makes every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithProxy(proxyURL string) UpdaterOpts {
+	return func(u *Updater) {
+		u.proxyURL = proxyURL
+	}
+}
+
+// withProxy returns a copy of client using the given proxy.
+// The given client is returned along with the error if the proxy can't be set, so that the [Updater] is always usable.
+func withProxy(client *http.Client, proxyURL string) (*http.Client, error) {
+	if proxyURL == "" {
+		return client, nil
+	}
+
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return client, fmt.Errorf("%w: malformed proxy url %q -> %w", ErrInvalidOption, proxyURL, err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return client, fmt.Errorf("%w: proxy url %q must be an absolute http(s) or socks5 url", ErrInvalidOption, proxyURL)
+	}
+
+	if parsed.Host == "" {
+		return client, fmt.Errorf("%w: proxy url %q must be an absolute http(s) or socks5 url", ErrInvalidOption, proxyURL)
+	}
+
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return client, fmt.Errorf("%w: can't set a proxy on a %T transport", ErrInvalidOption, t)
+	}
+	transport.Proxy = http.ProxyURL(parsed)
+
+	proxied := *client
+	proxied.Transport = transport
+
+	return &proxied, nil
+}
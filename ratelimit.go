@@ -0,0 +1,54 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// maxRateLimitWait is the longest the [Updater] sleeps waiting for a rate limit reset when [WithRateLimitWait] is enabled.
+const maxRateLimitWait = 5 * time.Minute
+
+// WithRateLimitWait makes the [Updater] sleep until the github (or [GitLabProvider]) rate limit resets, if it does within a few minutes, instead of failing with [ErrRateLimited].
+func WithRateLimitWait(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.rateLimitWait = enabled
+	}
+}
+
+// RateLimitError is returned when github rejects a call because of its rate limits. It wraps [ErrRateLimited].
+type RateLimitError struct {
+	// Reset is the time at which calls are allowed again.
+	Reset time.Time
+	err   error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s until %s -> %s", ErrRateLimited, e.Reset.Format(time.RFC3339), e.err)
+}
+
+func (e *RateLimitError) Unwrap() []error {
+	return []error{ErrRateLimited, e.err}
+}
+
+// asRateLimitError converts github rate limit errors to a [RateLimitError], it returns nil for any other error.
+func asRateLimitError(err error) *RateLimitError {
+	var (
+		rateErr  *github.RateLimitError
+		abuseErr *github.AbuseRateLimitError
+	)
+	switch {
+	case errors.As(err, &rateErr):
+		return &RateLimitError{Reset: rateErr.Rate.Reset.Time, err: err}
+	case errors.As(err, &abuseErr):
+		reset := time.Now()
+		if retryAfter := abuseErr.GetRetryAfter(); retryAfter > 0 {
+			reset = reset.Add(retryAfter)
+		}
+		return &RateLimitError{Reset: reset, err: err}
+	default:
+		return nil
+	}
+}
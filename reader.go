@@ -0,0 +1,21 @@
+package selfupdater
+
+import (
+	"context"
+	"io"
+)
+
+// contextReader stops reading from the underlying reader as soon as its context is done,
+// so that a cancelled download doesn't wait for the whole stream to be consumed.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (c *contextReader) Read(b []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return c.reader.Read(b)
+}
@@ -0,0 +1,68 @@
+package selfupdater
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+// cancelProvider is a [fakeProvider] whose downloads cancel the context once the first bytes are read.
+type cancelProvider struct {
+	*fakeProvider
+	cancel context.CancelFunc
+}
+
+type cancellingReader struct {
+	reader io.Reader
+	cancel context.CancelFunc
+	read   bool
+}
+
+func (r *cancellingReader) Read(b []byte) (int, error) {
+	if r.read {
+		r.cancel()
+	}
+	r.read = true
+	return r.reader.Read(b[:min(len(b), 4)])
+}
+
+func (p *cancelProvider) DownloadAsset(ctx context.Context, id int64) (io.ReadCloser, error) {
+	reader, err := p.fakeProvider.DownloadAsset(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(&cancellingReader{reader: reader, cancel: p.cancel}), nil
+}
+
+func TestDownloadCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	provider := &cancelProvider{fakeProvider: &fakeProvider{}, cancel: cancel}
+	provider.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": elfBinary("a large release asset")})
+
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "repo")
+	if err := os.WriteFile(exePath, elfBinary("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithContext(ctx), WithProvider(provider), WithTargetPath(exePath),
+		WithTempDir(dir), WithPlatform("linux", "amd64"), WithLaunchVerification(false))
+
+	err := u.Update()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled update returned %v", err)
+	}
+
+	downloads, _ := filepath.Glob(filepath.Join(dir, ".repo-update", "*"))
+	if len(downloads) != 0 {
+		t.Errorf("cancelled download left %v", downloads)
+	}
+	checkFile(t, exePath, elfBinary("v1"))
+}
@@ -0,0 +1,67 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+)
+
+// Recover repairs an install interrupted by a crash or a power loss, call it on startup before anything else touches the executable.
+// If the executable is missing, the swap is completed with the new binary staged next to it, or the `-old` binary is restored otherwise.
+// If the executable is in place, the binaries staged by an interrupted install are removed. It is a no-op when there is nothing to recover
+// and fails with [ErrRollbackFailed] if the executable is missing with no binary to restore it from.
+func (u *Updater) Recover() error {
+	unlock, err := u.lockUpdate()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	exePath, err := u.resolveExePath()
+	if err != nil {
+		return err
+	}
+	u.exePath = exePath
+
+	dir, base := filepath.Dir(exePath), filepath.Base(exePath)
+	newPath := filepath.Join(dir, "."+base+".new")
+	staged := []string{newPath, filepath.Join(dir, "."+base+".download")}
+
+	_, err = u.fsys.Stat(exePath)
+	if err == nil {
+		for _, path := range staged {
+			if err := u.fsys.Remove(path); err == nil {
+				u.logger.Info("removed the binary staged by an interrupted install", "path", path)
+			}
+		}
+		return nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check executable %s -> %w", exePath, err)
+	}
+
+	// the new binary is only staged once downloaded and verified.
+	if checkBinaryFormat(u.fsys, newPath, u.goos) == nil {
+		u.logger.Info("completing the interrupted install", "from", newPath, "path", exePath)
+		err = u.fsys.Rename(newPath, exePath)
+		if err == nil {
+			return nil
+		}
+		u.logger.Debug("failed to complete the interrupted install", "path", exePath, "error", err)
+	}
+
+	old := u.oldPath()
+	if _, err := u.fsys.Stat(old); err != nil {
+		return fmt.Errorf("%w: executable %s is missing and there is no old binary to restore -> %w", ErrRollbackFailed, exePath, err)
+	}
+
+	u.logger.Info("restoring the old binary after an interrupted install", "old", old, "path", exePath)
+	err = moveFile(u.fsys, old, exePath)
+	if err != nil {
+		return fmt.Errorf("%w: failed to restore the old binary -> %w", ErrRollbackFailed, err)
+	}
+	u.fsys.Remove(newPath)
+
+	return nil
+}
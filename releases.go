@@ -0,0 +1,287 @@
+package selfupdater
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// WithPrereleases makes the [Updater] consider prereleases (like `v1.5.0-beta.2`) when looking for the latest version.
+// Versions are ranked following semver precedence, so `1.5.0-beta.2` is older than `1.5.0`.
+func WithPrereleases(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.prereleases = enabled
+	}
+}
+
+// ReleaseInfo describes a github release of the repository.
+type ReleaseInfo struct {
+	Version     semver.Version
+	Tag         string
+	Name        string
+	PublishedAt time.Time
+	Prerelease  bool
+	// Body is the description of the release, usually its changelog.
+	Body    string
+	HTMLURL string
+	assets  []*github.ReleaseAsset
+}
+
+func newReleaseInfo(rel *github.RepositoryRelease, v semver.Version) ReleaseInfo {
+	return ReleaseInfo{
+		Version:     v,
+		Tag:         rel.GetTagName(),
+		Name:        rel.GetName(),
+		PublishedAt: rel.GetPublishedAt().Time,
+		Prerelease:  rel.GetPrerelease(),
+		Body:        rel.GetBody(),
+		HTMLURL:     rel.GetHTMLURL(),
+		assets:      rel.Assets,
+	}
+}
+
+func (u *Updater) listReleases() ([]*github.RepositoryRelease, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	var releases []*github.RepositoryRelease
+
+	page := 0
+	for {
+		var (
+			releasesPage []*github.RepositoryRelease
+			next         int
+		)
+		err := u.retry(func() (err error) {
+			releasesPage, next, err = u.provider.ListReleases(u.ctx, page)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list releases -> %w", err)
+		}
+
+		releases = append(releases, releasesPage...)
+
+		if next == 0 {
+			return releases, nil
+		}
+		page = next
+	}
+}
+
+// ListVersions returns every release of the repository whose tag is a valid semver, sorted from the newest to the oldest version.
+// Releases with a tag that can't be parsed are skipped.
+func (u *Updater) ListVersions() ([]ReleaseInfo, error) {
+	defer u.withDeadline()()
+
+	releases, err := u.listReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]ReleaseInfo, 0, len(releases))
+	for _, rel := range releases {
+		v, err := u.parseTag(rel.GetTagName())
+		if err != nil {
+			continue
+		}
+		infos = append(infos, newReleaseInfo(rel, v))
+	}
+
+	slices.SortFunc(infos, func(a, b ReleaseInfo) int {
+		return compareVersions(b.Version, a.Version)
+	})
+
+	return infos, nil
+}
+
+// LatestRelease returns the latest release, with its release notes so that they can be shown to the user.
+// It is a snapshot of the release resolved by the last check for update ([Updater.CheckLatest], [Updater.CheckForUpdate], [Updater.Update], ...),
+// which nothing is re-fetched for: a long running process must check for update again to see a release published since.
+// The latest release is only fetched if no check happened yet.
+func (u *Updater) LatestRelease() (*ReleaseInfo, error) {
+	if u.latest != nil {
+		return u.latest, nil
+	}
+
+	defer u.withDeadline()()
+
+	_, _, err := u.latestRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	return u.latest, nil
+}
+
+// latestRelease returns the latest release and its version, and keeps it for [Updater.LatestRelease]: every check for update refreshes it.
+func (u *Updater) latestRelease() (*github.RepositoryRelease, semver.Version, error) {
+	if u.err != nil {
+		return nil, semver.Version{}, u.err
+	}
+
+	u.logger.Debug("resolving latest release", "owner", u.Owner, "repo", u.Repo, "prereleases", u.prereleases)
+	rel, latest, err := u.cachedLatestRelease()
+	if err != nil {
+		u.logger.Debug("failed to resolve latest release", "error", err)
+		return nil, semver.Version{}, err
+	}
+
+	err = u.loadManifest(rel)
+	if err != nil {
+		return nil, semver.Version{}, err
+	}
+	if u.manifest != nil {
+		latest = u.manifestVersion()
+	}
+	u.logger.Info("latest release resolved", "tag", rel.GetTagName(), "version", latest.String(), "current", u.Current.String())
+
+	info := newReleaseInfo(rel, latest)
+	u.latest = &info
+
+	return rel, latest, nil
+}
+
+// WithIncludeDrafts makes the [Updater] consider draft releases when looking for the latest version, so that internal builds can update to unpublished releases.
+// Drafts are only listed to authenticated users with push access to the repository, see [WithToken].
+func WithIncludeDrafts(enabled bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.drafts = enabled
+	}
+}
+
+// WithVersionConstraint restricts the releases the [Updater] updates to (see [Updater.CheckLatest]) to the versions satisfying constraint,
+// in semver range syntax like `>=2.0.0 <3.0.0` to stay on 2.x. The highest matching version is picked.
+// An invalid constraint makes every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithVersionConstraint(constraint string) UpdaterOpts {
+	return func(u *Updater) {
+		u.constraint = constraint
+	}
+}
+
+// WithReleaseFilter makes the [Updater] skip the releases for which keep returns false when looking for the latest version (see [Updater.CheckLatest]),
+// like releases yanked after the fact, so that the highest version among the kept releases is picked. It doesn't apply to [Updater.UpdateToVersion].
+// The latest release of a filtered lookup is never cached (see [WithCacheTTL]), as the filter can't be told apart from another one.
+func WithReleaseFilter(keep func(rel ReleaseInfo) bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.releaseFilter = keep
+	}
+}
+
+// UpdatePolicy restricts how far an update may advance from the current version, see [WithAutoUpdatePolicy].
+type UpdatePolicy int
+
+const (
+	// PolicyAll allows updating to any newer version (the default).
+	PolicyAll UpdatePolicy = iota
+	// PolicyMinorAndPatch allows updating within the current major version (1.4.2 to 1.7.0 but not 2.0.0).
+	PolicyMinorAndPatch
+	// PolicyPatchOnly allows updating within the current minor version (1.4.2 to 1.4.5 but not 1.5.0).
+	PolicyPatchOnly
+)
+
+// WithAutoUpdatePolicy restricts the releases the [Updater] updates to (see [Updater.CheckLatest]) according to how far they advance from the current version.
+// The highest version allowed by the policy is picked.
+func WithAutoUpdatePolicy(policy UpdatePolicy) UpdaterOpts {
+	return func(u *Updater) {
+		u.policy = policy
+	}
+}
+
+// allowedVersion tells whether v satisfies the version constraint and the update policy.
+func (u *Updater) allowedVersion(v semver.Version) bool {
+	if u.versionRange != nil && !u.versionRange(v) {
+		return false
+	}
+
+	switch u.policy {
+	case PolicyMinorAndPatch:
+		return v.Major == u.Current.Major
+	case PolicyPatchOnly:
+		return v.Major == u.Current.Major && v.Minor == u.Current.Minor
+	default:
+		return true
+	}
+}
+
+func parseConstraint(constraint string) (semver.Range, error) {
+	if constraint == "" {
+		return nil, nil
+	}
+
+	versionRange, err := semver.ParseRange(constraint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed version constraint %q -> %w", ErrInvalidOption, constraint, err)
+	}
+
+	return versionRange, nil
+}
+
+// fetchLatestRelease returns the latest release and its version.
+// Github's latest release never is a prerelease nor a draft and may not satisfy the version constraint or the update policy,
+// so when prereleases, drafts, a constraint, a policy or a filter are enabled every release is fetched to find the highest version.
+func (u *Updater) fetchLatestRelease() (*github.RepositoryRelease, semver.Version, error) {
+	if !u.prereleases && !u.drafts && u.versionRange == nil && u.policy == PolicyAll && u.releaseFilter == nil {
+		var rel *github.RepositoryRelease
+		err := u.retry(func() (err error) {
+			rel, err = u.provider.LatestRelease(u.ctx)
+			return err
+		})
+		if err != nil {
+			return nil, semver.Version{}, err
+		}
+
+		latest, err := u.parseTag(rel.GetTagName())
+		if err != nil {
+			return nil, semver.Version{}, err
+		}
+
+		return rel, latest, nil
+	}
+
+	releases, err := u.listReleases()
+	if err != nil {
+		return nil, semver.Version{}, err
+	}
+
+	var (
+		latestRel *github.RepositoryRelease
+		latest    semver.Version
+	)
+	for _, rel := range releases {
+		if rel.GetDraft() && !u.drafts {
+			continue
+		}
+
+		v, err := u.parseTag(rel.GetTagName())
+		if err != nil {
+			continue
+		}
+
+		if !u.prereleases && (rel.GetPrerelease() || len(v.Pre) > 0) || !u.allowedVersion(v) {
+			continue
+		}
+
+		if u.releaseFilter != nil && !u.releaseFilter(newReleaseInfo(rel, v)) {
+			continue
+		}
+
+		if latestRel == nil || u.isNewer(v, latest) {
+			latestRel, latest = rel, v
+		}
+	}
+
+	if latestRel == nil && (u.versionRange != nil || u.policy != PolicyAll || u.releaseFilter != nil) {
+		return nil, semver.Version{}, fmt.Errorf("%w: no release with a semver tag allowed by the version constraint %q, update policy and release filter", ErrReleaseNotFound, u.constraint)
+	}
+
+	if latestRel == nil {
+		return nil, semver.Version{}, fmt.Errorf("%w: no release with a semver tag", ErrReleaseNotFound)
+	}
+
+	return latestRel, latest, nil
+}
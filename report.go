@@ -0,0 +1,82 @@
+package selfupdater
+
+import (
+	"fmt"
+
+	"github.com/blang/semver"
+)
+
+// AssetMatch tells whether a release asset matches the platform, and why it doesn't.
+type AssetMatch struct {
+	Name        string
+	ContentType string
+	Matched     bool
+	// Selected tells whether it is the asset [Updater.Update] would download, among the matching ones.
+	Selected bool
+	// Reason explains why the asset is skipped, like `no arm64 arch token`. It is empty for a matching asset.
+	Reason string
+}
+
+// MatchReport is the result of [Updater.MatchReport].
+type MatchReport struct {
+	Target semver.Version
+	// Platform is the platform the assets are matched against, like `linux-amd64` or `linux-armv7`.
+	Platform string
+	// Libc is the libc variant matched (`musl` or `gnu`), empty outside linux.
+	Libc   string
+	Assets []AssetMatch
+}
+
+// MatchReport fetches the latest release and tells, for every asset, whether it matches the platform and why it doesn't,
+// to troubleshoot an [ErrAssetNotFound] without guessing the naming conventions. Nothing is downloaded.
+func (u *Updater) MatchReport() (*MatchReport, error) {
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	defer u.withDeadline()()
+
+	rel, latest, err := u.latestRelease()
+	if err != nil {
+		return nil, err
+	}
+
+	u.assets = rel.Assets
+	u.target = latest
+
+	match, searched, err := u.assetMatch()
+	if err != nil {
+		return nil, err
+	}
+
+	platform := u.platform
+	if u.goarch == "arm" {
+		platform = fmt.Sprintf("%s-%s", u.goos, u.armPlatform())
+	}
+
+	report := &MatchReport{Target: latest, Platform: platform, Libc: u.libc}
+
+	var selected string
+	if asset, err := u.getAsset(); err == nil {
+		selected = asset.GetName()
+	}
+
+	for _, ra := range u.assets {
+		m := AssetMatch{Name: ra.GetName(), ContentType: ra.GetContentType()}
+		switch {
+		case !match(m.Name) && u.assetMatcher == nil && u.assetPattern == nil && u.assetTmpl == nil:
+			m.Reason = u.platformMismatch(m.Name)
+		case !match(m.Name):
+			m.Reason = "not " + searched
+		case u.contentType != "" && mediaType(m.ContentType) != mediaType(u.contentType):
+			m.Reason = fmt.Sprintf("content type %s, not %s", m.ContentType, u.contentType)
+		default:
+			m.Matched = true
+			m.Selected = m.Name == selected
+		}
+
+		report.Assets = append(report.Assets, m)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package selfupdater
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+func restart(exePath string, args, env []string) error {
+	err := syscall.Exec(exePath, append([]string{os.Args[0]}, args...), env)
+	return fmt.Errorf("failed to restart %s -> %w", exePath, err)
+}
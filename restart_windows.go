@@ -0,0 +1,20 @@
+//go:build windows
+
+package selfupdater
+
+import (
+	"fmt"
+	"os"
+)
+
+// restart spawns the new binary and exits, as windows has no way to replace the current process image.
+func restart(exePath string, args, env []string) error {
+	err := launchCommand(exePath, args, env).Start()
+	if err != nil {
+		return fmt.Errorf("failed to restart %s -> %w", exePath, err)
+	}
+
+	os.Exit(0)
+
+	return nil
+}
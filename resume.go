@@ -0,0 +1,56 @@
+package selfupdater
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// partPath returns the path the release asset is downloaded to until complete.
+// It is named after the asset ID so that a partial download left by a previous attempt is only resumed for the very same asset,
+// not for an asset of the same name from another release, or uploaded again.
+func (u *Updater) partPath() string {
+	return fmt.Sprintf("%s.%d.part", u.tmpPath, u.assetID)
+}
+
+// removeStaleParts removes the partial downloads of other assets of the same name.
+func (u *Updater) removeStaleParts() {
+	entries, err := u.fsys.ReadDir(u.tmpDir)
+	if err != nil {
+		return
+	}
+
+	current := filepath.Base(u.partPath())
+	for _, entry := range entries {
+		name := entry.Name()
+		rest, ok := strings.CutPrefix(name, u.assetName+".")
+		if !ok || name == current {
+			continue
+		}
+		// `<asset>.part` is how partial downloads used to be named, regardless of the asset ID.
+		id, ok := strings.CutSuffix(rest, ".part")
+		if _, err := strconv.ParseInt(id, 10, 64); rest == "part" || ok && err == nil {
+			u.logger.Debug("removing stale partial download", "path", name)
+			u.fsys.Remove(filepath.Join(u.tmpDir, name))
+		}
+	}
+}
+
+// openAssetFrom streams the given release asset starting at offset, if the provider supports it.
+// It tells whether the download is resumed: otherwise the asset is streamed from the beginning.
+func (u *Updater) openAssetFrom(id int64, offset int64) (io.ReadCloser, bool, error) {
+	rd, ok := u.provider.(rangeDownloader)
+	if offset == 0 || !ok || u.assetURL != "" {
+		reader, err := u.openAsset(id)
+		return reader, false, err
+	}
+
+	reader, resumed, err := rd.DownloadAssetFrom(u.ctx, id, offset)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: failed to resume release asset download -> %w", ErrDownloadFailed, err)
+	}
+
+	return reader, resumed, nil
+}
@@ -0,0 +1,139 @@
+package selfupdater
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/google/go-github/v59/github"
+)
+
+// rangeProvider is a [fakeProvider] able to resume downloads.
+type rangeProvider struct {
+	*fakeProvider
+	offsets []int64
+}
+
+func (p *rangeProvider) DownloadAssetFrom(_ context.Context, id int64, offset int64) (io.ReadCloser, bool, error) {
+	p.offsets = append(p.offsets, offset)
+	return io.NopCloser(bytes.NewReader(p.content[id][offset:])), true, nil
+}
+
+func resumeUpdater(t *testing.T, asset []byte) (*Updater, *rangeProvider, string) {
+	t.Helper()
+
+	provider := &rangeProvider{fakeProvider: &fakeProvider{}}
+	provider.addRelease("v1.1.0", map[string][]byte{"repo_linux_amd64": asset})
+
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "repo")
+	if err := os.WriteFile(exePath, elfBinary("v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithTargetPath(exePath), WithTempDir(dir),
+		WithPlatform("linux", "amd64"), WithLaunchVerification(false))
+
+	downloads := filepath.Join(dir, ".repo-update")
+	if err := os.MkdirAll(downloads, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return u, provider, downloads
+}
+
+func checkInstalled(t *testing.T, u *Updater, expected []byte) {
+	t.Helper()
+
+	content, err := os.ReadFile(u.exePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, expected) {
+		t.Errorf("installed binary is %q, expected %q", content, expected)
+	}
+}
+
+func TestResumeSameAsset(t *testing.T) {
+	asset := elfBinary("version 1.1.0")
+	u, provider, downloads := resumeUpdater(t, asset)
+
+	if err := os.WriteFile(filepath.Join(downloads, "repo_linux_amd64.1.part"), asset[:6], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(provider.offsets, []int64{6}) {
+		t.Errorf("download resumed from %v, expected 6", provider.offsets)
+	}
+	checkInstalled(t, u, asset)
+}
+
+func TestResumeStaleBytes(t *testing.T) {
+	asset := elfBinary("version 1.1.0")
+	u, provider, downloads := resumeUpdater(t, asset)
+
+	// the release publishes checksums, which are checked without WithChecksumVerification as the digest doesn't cover the resumed bytes.
+	sum := sha256.Sum256(asset)
+	checksums := []byte(hex.EncodeToString(sum[:]) + "  repo_linux_amd64\n")
+	provider.content[2] = checksums
+	provider.releases[0].Assets = append(provider.releases[0].Assets,
+		&github.ReleaseAsset{ID: github.Int64(2), Name: github.String("checksums.txt"), Size: github.Int(len(checksums))})
+
+	// a partial download whose bytes don't match the asset anymore.
+	if err := os.WriteFile(filepath.Join(downloads, "repo_linux_amd64.1.part"), elfBinary("xx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := u.Update(); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+	checkFile(t, filepath.Join(filepath.Dir(downloads), "repo"), elfBinary("v1"))
+
+	// the corrupted download is started over rather than resumed again.
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(provider.offsets, []int64{6}) {
+		t.Errorf("download resumed from %v, expected 6 only", provider.offsets)
+	}
+	checkInstalled(t, u, asset)
+}
+
+func TestResumeOtherAsset(t *testing.T) {
+	asset := elfBinary("version 1.1.0")
+	u, provider, downloads := resumeUpdater(t, asset)
+
+	// partial downloads of an asset of the same name from another release, and from before they were named by ID.
+	stale := []string{filepath.Join(downloads, "repo_linux_amd64.42.part"), filepath.Join(downloads, "repo_linux_amd64.part")}
+	for _, path := range stale {
+		if err := os.WriteFile(path, elfBinary("ver"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := u.Update(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(provider.offsets) != 0 {
+		t.Errorf("download resumed from %v", provider.offsets)
+	}
+	checkInstalled(t, u, asset)
+	for _, path := range stale {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("stale partial download %s not removed", path)
+		}
+	}
+}
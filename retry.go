@@ -0,0 +1,111 @@
+package selfupdater
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// WithRetry makes the [Updater] retry github API calls and asset downloads up to attempts times when they fail with a transient error
+// (network error, 5xx status, ...). The delay between two attempts starts at backoff and doubles each time, with some jitter.
+// Client errors like a 404 are never retried. A newer release without assets yet ([ErrNoAssetsYet]) is polled the same way until they are uploaded.
+func WithRetry(attempts int, backoff time.Duration) UpdaterOpts {
+	return func(u *Updater) {
+		u.retryAttempts = attempts
+		u.retryBackoff = backoff
+	}
+}
+
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var (
+		rateErr  *github.RateLimitError
+		abuseErr *github.AbuseRateLimitError
+		respErr  *github.ErrorResponse
+		netErr   net.Error
+	)
+	switch {
+	case errors.As(err, &rateErr), errors.As(err, &abuseErr):
+		return false
+	case errors.As(err, &respErr) && respErr.Response != nil:
+		code := respErr.Response.StatusCode
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+	case errors.As(err, &netErr):
+		return true
+	default:
+		return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, ErrIncompleteDownload) || errors.Is(err, ErrNoAssetsYet)
+	}
+}
+
+// retry calls fn until it succeeds, fails with a non transient error, or the attempts configured with [WithRetry] are exhausted.
+// It gives up early if the context deadline would be reached before the next attempt.
+func (u *Updater) retry(fn func() error) error {
+	delay := u.retryBackoff
+
+	var (
+		err        error
+		waitedRate bool
+	)
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if rateErr := asRateLimitError(err); rateErr != nil {
+			if waitedRate || !u.waitRateLimit(rateErr.Reset) {
+				return rateErr
+			}
+			waitedRate = true
+			attempt--
+			continue
+		}
+
+		if err == nil || attempt >= u.retryAttempts || !isTransient(err) {
+			return err
+		}
+
+		wait := delay
+		if delay > 0 {
+			wait = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		}
+
+		if deadline, ok := u.ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+
+		select {
+		case <-u.ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+
+		delay *= 2
+	}
+}
+
+// waitRateLimit sleeps until reset if [WithRateLimitWait] is enabled and reset is close enough.
+// It returns false if it didn't wait or the context is done before reset.
+func (u *Updater) waitRateLimit(reset time.Time) bool {
+	wait := time.Until(reset)
+	if !u.rateLimitWait || wait > maxRateLimitWait {
+		return false
+	}
+
+	if deadline, ok := u.ctx.Deadline(); ok && deadline.Before(reset) {
+		return false
+	}
+
+	select {
+	case <-u.ctx.Done():
+		return false
+	case <-time.After(wait):
+		return true
+	}
+}
@@ -0,0 +1,91 @@
+package selfupdater
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ServiceManager is the service manager running the app, see [WithServiceManager].
+type ServiceManager int
+
+const (
+	// ServiceNone means the app is not run by a service manager.
+	ServiceNone ServiceManager = iota
+	// ServiceSystemd is systemd, the service is a unit name like `myagent.service`.
+	ServiceSystemd
+	// ServiceLaunchd is launchd, the service is a service target like `system/com.example.myagent`.
+	ServiceLaunchd
+	// ServiceWindows is the windows service control manager, the service is a service name.
+	ServiceWindows
+)
+
+func (s ServiceManager) String() string {
+	switch s {
+	case ServiceSystemd:
+		return "systemd"
+	case ServiceLaunchd:
+		return "launchd"
+	case ServiceWindows:
+		return "windows-service"
+	default:
+		return "none"
+	}
+}
+
+// WithServiceManager makes the [Updater] restart the given service through its service manager once the new binary is installed,
+// instead of test launching the binary (which would conflict with the service manager). Restarting the service usually stops the current process.
+// As the new binary is not test launched, it is not automatically rolled back.
+// Restarting a service usually requires root (or administrator) rights.
+func WithServiceManager(kind ServiceManager, service string) UpdaterOpts {
+	return func(u *Updater) {
+		u.serviceManager = kind
+		u.service = service
+	}
+}
+
+// detectServiceManager tells whether the current process is run by a service manager.
+func detectServiceManager() ServiceManager {
+	switch {
+	case os.Getenv("INVOCATION_ID") != "":
+		return ServiceSystemd
+	case os.Getenv("XPC_SERVICE_NAME") != "" && os.Getenv("XPC_SERVICE_NAME") != "0":
+		return ServiceLaunchd
+	case isWindowsService():
+		return ServiceWindows
+	default:
+		return ServiceNone
+	}
+}
+
+// restartService asks the service manager to restart the service. The command is not waited for
+// when it is expected to stop the current process.
+func (u *Updater) restartService() error {
+	var cmd *exec.Cmd
+	switch u.serviceManager {
+	case ServiceSystemd:
+		cmd = exec.Command("systemctl", "--no-block", "restart", u.service)
+	case ServiceLaunchd:
+		cmd = exec.Command("launchctl", "kickstart", "-k", u.service)
+	case ServiceWindows:
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command",
+			fmt.Sprintf("Restart-Service -Force -Name '%s'", strings.ReplaceAll(u.service, "'", "''")))
+	default:
+		return nil
+	}
+
+	u.logger.Info("restarting the service", "manager", u.serviceManager.String(), "service", u.service)
+	var err error
+	if u.serviceManager == ServiceSystemd {
+		// --no-block returns once the restart is queued.
+		err = cmd.Run()
+	} else {
+		err = cmd.Start()
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s restart of %s -> %w", ErrServiceRestart, u.serviceManager, u.service, err)
+	}
+
+	return nil
+}
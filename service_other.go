@@ -0,0 +1,8 @@
+//go:build !windows
+
+package selfupdater
+
+// isWindowsService is always false outside windows.
+func isWindowsService() bool {
+	return false
+}
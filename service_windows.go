@@ -0,0 +1,11 @@
+//go:build windows
+
+package selfupdater
+
+import "golang.org/x/sys/windows/svc"
+
+// isWindowsService tells whether the current process is run by the service control manager.
+func isWindowsService() bool {
+	ok, err := svc.IsWindowsService()
+	return err == nil && ok
+}
@@ -0,0 +1,100 @@
+package selfupdater
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/google/go-github/v59/github"
+)
+
+// WithGPGPublicKey enables the verification of the detached GPG signature of the downloaded asset.
+// key is the public key (armored or binary) the release assets are signed with. The signature is expected in a
+// `<asset name>.sig` or `<asset name>.asc` asset of the same release.
+func WithGPGPublicKey(key []byte) UpdaterOpts {
+	return func(u *Updater) {
+		u.gpgKey = key
+	}
+}
+
+func (u *Updater) getSignatureAsset(extensions ...string) *github.ReleaseAsset {
+	index := slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
+		return slices.ContainsFunc(extensions, func(ext string) bool {
+			return ra.GetName() == u.assetName+ext
+		})
+	})
+
+	if index == -1 {
+		return nil
+	}
+
+	return u.assets[index]
+}
+
+func (u *Updater) fetchSignature(asset *github.ReleaseAsset) ([]byte, error) {
+	reader, err := u.openAsset(asset.GetID())
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	sig, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature asset %s -> %w", asset.GetName(), err)
+	}
+
+	return sig, nil
+}
+
+func readKeyRing(key []byte) (openpgp.EntityList, error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+	if err == nil {
+		return keyring, nil
+	}
+
+	return openpgp.ReadKeyRing(bytes.NewReader(key))
+}
+
+func (u *Updater) verifyGPGSignature() error {
+	if len(u.gpgKey) == 0 {
+		return nil
+	}
+
+	keyring, err := readKeyRing(u.gpgKey)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read gpg public key -> %w", ErrSignatureVerification, err)
+	}
+
+	asset := u.getSignatureAsset(".sig", ".asc")
+	if asset == nil {
+		return fmt.Errorf("%w: no signature asset for %s", ErrSignatureVerification, u.assetName)
+	}
+
+	var sig []byte
+	err = u.retry(func() (err error) {
+		sig, err = u.fetchSignature(asset)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSignatureVerification, err)
+	}
+
+	f, err := u.openDownloaded()
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded release asset -> %w", err)
+	}
+	defer f.Close()
+
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte("-----BEGIN")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, f, bytes.NewReader(sig), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, f, bytes.NewReader(sig), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s -> %w", ErrSignatureVerification, asset.GetName(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package selfupdater
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// SLSAOptions configures the verification of the SLSA provenance of the release assets, see [WithSLSAVerification].
+type SLSAOptions struct {
+	// Roots is the PEM encoded bundle of the certificate authorities (e.g. the Fulcio root and intermediate certificates)
+	// the certificate signing the provenance must chain to. It is required.
+	Roots []byte
+	// BuilderID is the expected builder of the provenance, like
+	// `https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml`. It is required.
+	// Without a `@ref` suffix, any version of the builder is accepted.
+	BuilderID string
+	// Issuer is the expected OIDC issuer of the signing certificate, `https://token.actions.githubusercontent.com` (GitHub Actions) by default.
+	Issuer string
+	// RekorURL, like `https://rekor.sigstore.dev`, is the Rekor transparency log every signature of the provenance must have an entry in.
+	// The short-lived signing certificate is checked at the time the entry was logged. It is required.
+	RekorURL string
+	// RekorPublicKey is the PEM encoded public key of the Rekor instance, which signs the timestamp of its entries. It is required.
+	RekorPublicKey []byte
+	// SourceURI is the repository the asset must be built from, like `github.com/owner/repo`, which is the default with the owner and repo given to [New].
+	SourceURI string
+	// Asset is the name of the provenance asset of the release. By default, it is `<asset name>.intoto.jsonl`, or the only `.intoto.jsonl` asset of the release.
+	Asset string
+}
+
+// WithSLSAVerification enables the verification of the SLSA provenance of the downloaded asset, as generated by the SLSA GitHub builders (an `.intoto.jsonl` asset
+// of DSSE envelopes): the provenance must be signed by a certificate of the builder chaining to the roots, list the digest of the asset as a subject, and come
+// from the expected builder and source repository. A missing or mismatching provenance aborts the update with [ErrProvenanceVerification].
+// Options without Roots, BuilderID, RekorURL or RekorPublicKey make every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithSLSAVerification(opts SLSAOptions) UpdaterOpts {
+	return func(u *Updater) {
+		if opts.Issuer == "" {
+			opts.Issuer = githubActionsIssuer
+		}
+		u.slsa = &opts
+	}
+}
+
+// githubActionsIssuer is the OIDC issuer of the GitHub Actions workflows, which the SLSA GitHub builders run in.
+const githubActionsIssuer = "https://token.actions.githubusercontent.com"
+
+func validateSLSA(opts *SLSAOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	if len(opts.Roots) == 0 || opts.BuilderID == "" || opts.RekorURL == "" || len(opts.RekorPublicKey) == 0 {
+		return fmt.Errorf("%w: SLSA verification requires Roots, BuilderID, RekorURL and RekorPublicKey", ErrInvalidOption)
+	}
+
+	return validateRekor(opts.RekorURL, opts.RekorPublicKey)
+}
+
+// dsseEnvelope is a line of an `.intoto.jsonl` provenance asset.
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+	Signatures  []struct {
+		Sig  string `json:"sig"`
+		Cert string `json:"cert"`
+	} `json:"signatures"`
+}
+
+type provenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// provenanceStatement is the in-toto statement of a SLSA provenance, v0.2 and v1 predicates.
+type provenanceStatement struct {
+	Subject   []provenanceSubject `json:"subject"`
+	Predicate struct {
+		Builder struct {
+			ID string `json:"id"`
+		} `json:"builder"`
+		Invocation struct {
+			ConfigSource struct {
+				URI string `json:"uri"`
+			} `json:"configSource"`
+		} `json:"invocation"`
+		RunDetails struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+		} `json:"runDetails"`
+		BuildDefinition struct {
+			ExternalParameters struct {
+				Workflow struct {
+					Repository string `json:"repository"`
+				} `json:"workflow"`
+			} `json:"externalParameters"`
+		} `json:"buildDefinition"`
+	} `json:"predicate"`
+}
+
+func (s *provenanceStatement) builderID() string {
+	if s.Predicate.RunDetails.Builder.ID != "" {
+		return s.Predicate.RunDetails.Builder.ID
+	}
+	return s.Predicate.Builder.ID
+}
+
+func (s *provenanceStatement) sourceURI() string {
+	if s.Predicate.BuildDefinition.ExternalParameters.Workflow.Repository != "" {
+		return s.Predicate.BuildDefinition.ExternalParameters.Workflow.Repository
+	}
+	return s.Predicate.Invocation.ConfigSource.URI
+}
+
+// matchBuilder compares builder ids, ignoring the ref of id if expected has none.
+func matchBuilder(id, expected string) bool {
+	if !strings.Contains(expected, "@") {
+		id, _, _ = strings.Cut(id, "@")
+	}
+	return id == expected
+}
+
+// normalizeSourceURI reduces a source URI like `git+https://github.com/owner/repo@refs/tags/v1.2.3` to `github.com/owner/repo`.
+func normalizeSourceURI(uri string) string {
+	uri = strings.TrimPrefix(uri, "git+")
+	uri = strings.TrimPrefix(uri, "https://")
+	uri, _, _ = strings.Cut(uri, "@")
+	return strings.TrimSuffix(strings.ToLower(uri), ".git")
+}
+
+// dssePAE is the pre-authentication encoding of a DSSE payload, which is what gets signed.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+func (u *Updater) verifySLSAProvenance() error {
+	if u.slsa == nil {
+		return nil
+	}
+
+	err := u.checkSLSAProvenance()
+	if err != nil {
+		return fmt.Errorf("%w: %s -> %w", ErrProvenanceVerification, u.assetName, err)
+	}
+
+	return nil
+}
+
+// provenanceAsset returns the provenance asset of the release, see [SLSAOptions.Asset].
+func (u *Updater) provenanceAsset() *github.ReleaseAsset {
+	if u.slsa.Asset != "" {
+		index := slices.IndexFunc(u.assets, func(ra *github.ReleaseAsset) bool {
+			return ra.GetName() == u.slsa.Asset
+		})
+		if index == -1 {
+			return nil
+		}
+		return u.assets[index]
+	}
+
+	if asset := u.getSignatureAsset(".intoto.jsonl"); asset != nil {
+		return asset
+	}
+
+	var found *github.ReleaseAsset
+	for _, ra := range u.assets {
+		if strings.HasSuffix(ra.GetName(), ".intoto.jsonl") {
+			if found != nil {
+				return nil
+			}
+			found = ra
+		}
+	}
+
+	return found
+}
+
+func (u *Updater) checkSLSAProvenance() error {
+	asset := u.provenanceAsset()
+	if asset == nil {
+		return errors.New("no provenance asset")
+	}
+
+	var content []byte
+	err := u.retry(func() (err error) {
+		content, err = u.fetchSignature(asset)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	digest, err := u.downloadedHash(sha256.New())
+	if err != nil {
+		return err
+	}
+
+	// a provenance asset may hold several envelopes, one of them must be valid for the asset.
+	err = errors.New("empty provenance")
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		err = u.checkProvenanceEnvelope(line, digest)
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s -> %w", asset.GetName(), err)
+}
+
+// checkProvenanceEnvelope verifies a DSSE envelope of the provenance against the hex encoded sha256 digest of the asset.
+func (u *Updater) checkProvenanceEnvelope(line []byte, digest string) error {
+	var envelope dsseEnvelope
+	if err := json.Unmarshal(line, &envelope); err != nil {
+		return fmt.Errorf("invalid envelope -> %w", err)
+	}
+	if envelope.PayloadType != "application/vnd.in-toto+json" {
+		return fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return fmt.Errorf("invalid payload -> %w", err)
+	}
+
+	err = u.checkEnvelopeSignature(envelope, payload)
+	if err != nil {
+		return err
+	}
+
+	var statement provenanceStatement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return fmt.Errorf("invalid statement -> %w", err)
+	}
+
+	if !slices.ContainsFunc(statement.Subject, func(s provenanceSubject) bool {
+		return strings.EqualFold(s.Digest["sha256"], digest)
+	}) {
+		return fmt.Errorf("no subject with sha256 %s", digest)
+	}
+
+	if id := statement.builderID(); !matchBuilder(id, u.slsa.BuilderID) {
+		return fmt.Errorf("builder %q doesn't match %s", id, u.slsa.BuilderID)
+	}
+
+	source := u.slsa.SourceURI
+	if source == "" {
+		source = fmt.Sprintf("github.com/%s/%s", u.Owner, u.Repo)
+	}
+	if uri := statement.sourceURI(); normalizeSourceURI(uri) != normalizeSourceURI(source) {
+		return fmt.Errorf("source %q doesn't match %s", uri, source)
+	}
+
+	return nil
+}
+
+// checkEnvelopeSignature makes sure the envelope is signed by a certificate of the expected builder, and that the signature is logged in Rekor.
+func (u *Updater) checkEnvelopeSignature(envelope dsseEnvelope, payload []byte) error {
+	err := errors.New("no signature")
+	for _, s := range envelope.Signatures {
+		cert, errCert := parseCertificate([]byte(s.Cert))
+		if errCert != nil {
+			err = fmt.Errorf("invalid certificate -> %w", errCert)
+			continue
+		}
+
+		identities := certIdentities(cert)
+		if !slices.ContainsFunc(identities, func(id string) bool { return matchBuilder(id, u.slsa.BuilderID) }) {
+			err = fmt.Errorf("certificate identities [%s] don't match %s", strings.Join(identities, ", "), u.slsa.BuilderID)
+			continue
+		}
+
+		if issuer := certIssuer(cert); issuer != u.slsa.Issuer {
+			err = fmt.Errorf("certificate issuer %q doesn't match %s", issuer, u.slsa.Issuer)
+			continue
+		}
+
+		sig, errSig := base64.StdEncoding.DecodeString(s.Sig)
+		if errSig != nil {
+			err = fmt.Errorf("invalid signature -> %w", errSig)
+			continue
+		}
+
+		digest := sha256.Sum256(dssePAE(envelope.PayloadType, payload))
+		err = verifyDigestSignature(cert.PublicKey, digest[:], sig)
+		if err != nil {
+			continue
+		}
+
+		err = u.checkLoggedSignature(cert, hex.EncodeToString(digest[:]), sig, []byte(s.Cert))
+		if err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// checkLoggedSignature looks for the Rekor entry of an envelope signature, and checks the certificate chains to the roots at the time it was logged.
+func (u *Updater) checkLoggedSignature(cert *x509.Certificate, digest string, sig, signer []byte) error {
+	var integrated time.Time
+	err := u.retry(func() (err error) {
+		integrated, err = u.checkRekorEntry(u.slsa.RekorURL, u.slsa.RekorPublicKey, digest, sig, signer)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = verifyCertChain(cert, u.slsa.Roots, integrated)
+	if err != nil {
+		return fmt.Errorf("invalid certificate at %s -> %w", integrated.UTC().Format(time.RFC3339), err)
+	}
+
+	return nil
+}
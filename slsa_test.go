@@ -0,0 +1,138 @@
+package selfupdater
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+const testBuilderID = "https://github.com/owner/repo/.github/workflows/release.yml"
+
+// provenance returns an `.intoto.jsonl` provenance of the fixture asset signed with the fixture certificate, and the digest it was signed over.
+func (f *keylessFixture) provenance(t *testing.T) (content, digest, sig []byte) {
+	t.Helper()
+
+	assetDigest := sha256.Sum256(f.asset)
+	var statement provenanceStatement
+	statement.Subject = []provenanceSubject{{Name: "app_linux_amd64", Digest: map[string]string{"sha256": hex.EncodeToString(assetDigest[:])}}}
+	statement.Predicate.RunDetails.Builder.ID = testBuilderID + "@refs/tags/v1.1.0"
+	statement.Predicate.BuildDefinition.ExternalParameters.Workflow.Repository = "https://github.com/owner/repo"
+	payload, _ := json.Marshal(statement)
+
+	envelope := dsseEnvelope{PayloadType: "application/vnd.in-toto+json", Payload: base64.StdEncoding.EncodeToString(payload)}
+	sum := sha256.Sum256(dssePAE(envelope.PayloadType, payload))
+	sig, _ = ecdsa.SignASN1(rand.Reader, f.key, sum[:])
+	envelope.Signatures = append(envelope.Signatures, struct {
+		Sig  string `json:"sig"`
+		Cert string `json:"cert"`
+	}{base64.StdEncoding.EncodeToString(sig), string(f.certPEM)})
+
+	content, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content, sum[:], sig
+}
+
+func (f *keylessFixture) slsaUpdater(t *testing.T, opts SLSAOptions, content []byte) *Updater {
+	t.Helper()
+
+	provider := &fakeProvider{}
+	rel := provider.addRelease("v1.1.0", map[string][]byte{
+		"app_linux_amd64":              f.asset,
+		"app_linux_amd64.intoto.jsonl": content,
+	})
+
+	u := New("owner", "repo", semver.MustParse("1.0.0"), WithProvider(provider), WithSLSAVerification(opts))
+	if err := u.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	u.assets = rel.Assets
+	u.assetName = "app_linux_amd64"
+	u.tmpPath = filepath.Join(t.TempDir(), u.assetName)
+	if err := os.WriteFile(u.tmpPath, f.asset, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+func (f *keylessFixture) slsaOptions(rekorURL string) SLSAOptions {
+	return SLSAOptions{
+		Roots:          f.roots,
+		BuilderID:      testBuilderID,
+		RekorURL:       rekorURL,
+		RekorPublicKey: f.rekorKey,
+	}
+}
+
+func TestSLSAProvenance(t *testing.T) {
+	f := newKeylessFixture(t, time.Now().Add(-48*time.Hour))
+	content, digest, sig := f.provenance(t)
+	rekor := f.rekorEntryServer(t, digest, sig)
+	defer rekor.Close()
+
+	// the certificate expired long ago, it is checked at the time the signature was logged, and the issuer defaults to GitHub Actions.
+	err := f.slsaUpdater(t, f.slsaOptions(rekor.URL), content).verifySLSAProvenance()
+	if err != nil {
+		t.Fatalf("expected a valid provenance, got %v", err)
+	}
+}
+
+func TestSLSAProvenanceNotLogged(t *testing.T) {
+	f := newKeylessFixture(t, time.Now().Add(-48*time.Hour))
+	content, _, _ := f.provenance(t)
+	rekor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]string{})
+	}))
+	defer rekor.Close()
+
+	err := f.slsaUpdater(t, f.slsaOptions(rekor.URL), content).verifySLSAProvenance()
+	if !errors.Is(err, ErrProvenanceVerification) {
+		t.Fatalf("expected ErrProvenanceVerification, got %v", err)
+	}
+}
+
+func TestSLSAProvenanceWrongIssuer(t *testing.T) {
+	f := newKeylessFixture(t, time.Now())
+	content, digest, sig := f.provenance(t)
+	rekor := f.rekorEntryServer(t, digest, sig)
+	defer rekor.Close()
+
+	opts := f.slsaOptions(rekor.URL)
+	opts.Issuer = "https://accounts.google.com"
+	err := f.slsaUpdater(t, opts, content).verifySLSAProvenance()
+	if !errors.Is(err, ErrProvenanceVerification) {
+		t.Fatalf("expected ErrProvenanceVerification, got %v", err)
+	}
+}
+
+func TestSLSARequiresRekor(t *testing.T) {
+	f := newKeylessFixture(t, time.Now())
+
+	for name, opts := range map[string]SLSAOptions{
+		"rekor":     {Roots: f.roots, BuilderID: testBuilderID, RekorPublicKey: f.rekorKey},
+		"rekor key": {Roots: f.roots, BuilderID: testBuilderID, RekorURL: "https://rekor.sigstore.dev"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			u := New("owner", "repo", semver.MustParse("1.0.0"), WithSLSAVerification(opts))
+			if !errors.Is(u.Err(), ErrInvalidOption) {
+				t.Fatalf("expected ErrInvalidOption, got %v", u.Err())
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package selfupdater
+
+import "time"
+
+// UpdatePhase is the stage an [Updater] is at, see [Updater.Status].
+type UpdatePhase int
+
+const (
+	// PhaseIdle means no operation is in progress.
+	PhaseIdle UpdatePhase = iota
+	// PhaseChecking means the latest release is being fetched.
+	PhaseChecking
+	// PhaseDownloading means the release asset is being downloaded and verified.
+	PhaseDownloading
+	// PhaseReady means the release is downloaded and verified, waiting for [Updater.Apply].
+	PhaseReady
+	// PhaseInstalling means the new binary is being installed.
+	PhaseInstalling
+	// PhaseDone means the last update was installed.
+	PhaseDone
+	// PhaseFailed means the last operation failed, see [UpdateStatus.Err].
+	PhaseFailed
+)
+
+func (p UpdatePhase) String() string {
+	switch p {
+	case PhaseIdle:
+		return "idle"
+	case PhaseChecking:
+		return "checking"
+	case PhaseDownloading:
+		return "downloading"
+	case PhaseReady:
+		return "ready"
+	case PhaseInstalling:
+		return "installing"
+	case PhaseDone:
+		return "done"
+	case PhaseFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// UpdateStatus is a snapshot of what an [Updater] is doing, see [Updater.Status].
+type UpdateStatus struct {
+	Phase UpdatePhase
+	// Downloaded and Total are the progress of the download, Total is -1 if the asset size is unknown.
+	Downloaded int64
+	Total      int64
+	// Err is the error of the last operation if it failed.
+	Err error
+}
+
+// Percent returns the download progress in percent, or -1 if the asset size is unknown.
+func (s UpdateStatus) Percent() float64 {
+	if s.Total <= 0 {
+		return -1
+	}
+
+	return float64(s.Downloaded) * 100 / float64(s.Total)
+}
+
+// Status returns the current status of the [Updater].
+// It is safe to call from another goroutine while an update is in progress, so that a UI can poll it instead of using [WithProgress].
+func (u *Updater) Status() UpdateStatus {
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	return u.status
+}
+
+func (u *Updater) setPhase(phase UpdatePhase) {
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	u.status.Phase = phase
+	u.status.Err = nil
+	if phase == PhaseDownloading {
+		u.status.Downloaded, u.status.Total = 0, -1
+		u.rate = rateMeter{}
+	}
+}
+
+// fail records err as the error of the current operation and returns it.
+func (u *Updater) fail(err error) error {
+	u.statusMu.Lock()
+	defer u.statusMu.Unlock()
+
+	u.status.Phase = PhaseFailed
+	u.status.Err = err
+
+	return err
+}
+
+// reportProgress records the download progress and reports it to the functions given with [WithProgress] and [WithDetailedProgress].
+func (u *Updater) reportProgress(downloaded, total int64) {
+	u.statusMu.Lock()
+	u.status.Downloaded, u.status.Total = downloaded, total
+	rate := u.rate.update(downloaded, time.Now())
+	u.statusMu.Unlock()
+
+	if u.progress != nil {
+		u.progress(downloaded, total)
+	}
+	if u.detailedProgress != nil {
+		u.detailedProgress(newProgress(downloaded, total, rate))
+	}
+}
@@ -0,0 +1,68 @@
+package selfupdater
+
+import (
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// knownTagPrefixes are stripped from release tags before parsing them, the repository name followed by `-` or `/` also is.
+var knownTagPrefixes = []string{"release-", "release/", "version-", "version/"}
+
+// WithTagParser replaces the parsing of release tags into versions, for projects with unusual tag schemes.
+// Releases whose tag fails to parse are ignored.
+func WithTagParser(parse func(tag string) (semver.Version, error)) UpdaterOpts {
+	return func(u *Updater) {
+		u.tagParser = parse
+	}
+}
+
+func (u *Updater) parseTag(tag string) (semver.Version, error) {
+	if u.tagParser != nil {
+		return u.tagParser(tag)
+	}
+
+	return semver.Parse(u.normalizeTag(tag))
+}
+
+// normalizeTag turns tags like `v1.2.3`, `release-v1.2.3` or `myapp/1.2.3` into `1.2.3`.
+// Only a known prefix and a leading `v` or `V` are stripped, letters elsewhere in the tag are left untouched.
+func (u *Updater) normalizeTag(tag string) string {
+	prefixes := append([]string{u.Repo + "-", u.Repo + "/"}, knownTagPrefixes...)
+	for _, prefix := range prefixes {
+		if len(tag) > len(prefix) && strings.EqualFold(tag[:len(prefix)], prefix) {
+			tag = tag[len(prefix):]
+			break
+		}
+	}
+
+	if strings.HasPrefix(tag, "v") || strings.HasPrefix(tag, "V") {
+		tag = tag[1:]
+	}
+
+	return tag
+}
+
+// compareVersions compares versions by semver precedence: a prerelease (`1.0.0-rc.1`) is lower than its release (`1.0.0`)
+// and build metadata (`1.0.0+a` and `1.0.0+b`) is ignored, both versions being equal.
+func compareVersions(a, b semver.Version) int {
+	a.Build, b.Build = nil, nil
+	return a.Compare(b)
+}
+
+// WithVersionComparator replaces the semver precedence (see [UpdateAvailable]) deciding whether latest is newer than current,
+// for versioning schemes with their own ordering like date based builds. It also picks the highest release when every release is listed
+// (see [WithPrereleases]).
+func WithVersionComparator(newer func(latest, current semver.Version) bool) UpdaterOpts {
+	return func(u *Updater) {
+		u.newerVersion = newer
+	}
+}
+
+// isNewer tells whether latest is newer than current, with the comparator set with [WithVersionComparator] if any.
+func (u *Updater) isNewer(latest, current semver.Version) bool {
+	if u.newerVersion != nil {
+		return u.newerVersion(latest, current)
+	}
+	return compareVersions(latest, current) > 0
+}
@@ -0,0 +1,38 @@
+package selfupdater
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, test := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.1", "1.0.0-rc.2", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0+a", "1.0.0+b", 0},
+		{"1.0.0+b", "1.0.0", 0},
+		{"1.0.1+a", "1.0.0+b", 1},
+		{"1.0.0-rc.1+a", "1.0.0-rc.1+b", 0},
+	} {
+		if got := compareVersions(semver.MustParse(test.a), semver.MustParse(test.b)); got != test.expected {
+			t.Errorf("compareVersions(%s, %s) = %d, expected %d", test.a, test.b, got, test.expected)
+		}
+	}
+}
+
+func TestIsNewerIgnoresBuild(t *testing.T) {
+	u := New("owner", "repo", semver.MustParse("1.0.0+a"))
+
+	if u.isNewer(semver.MustParse("1.0.0+b"), u.Current) {
+		t.Error("a build of the same version is taken for a newer one")
+	}
+	if !u.isNewer(semver.MustParse("1.0.0"), semver.MustParse("1.0.0-rc.1")) {
+		t.Error("a release is not newer than its prerelease")
+	}
+}
@@ -0,0 +1,93 @@
+package selfupdater
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WithTargetPath makes the [Updater] install the new release at filePath instead of the current executable path.
+// Symlinks are resolved so that the real file is replaced, not the link.
+func WithTargetPath(filePath string) UpdaterOpts {
+	return func(u *Updater) {
+		u.targetPath = filePath
+	}
+}
+
+// resolveExePath returns the path of the binary to replace: the target path if set, the current executable otherwise, with symlinks resolved.
+func (u *Updater) resolveExePath() (string, error) {
+	exePath := u.targetPath
+	if exePath == "" {
+		var err error
+		exePath, err = executablePath()
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve current executable path, set it with WithTargetPath -> %w", err)
+		}
+	}
+
+	// a custom file system has no symlinks to resolve.
+	if u.customFileSystem() {
+		return exePath, nil
+	}
+
+	resolved, err := filepath.EvalSymlinks(exePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		// nothing installed yet (see [Updater.InstallOnly]) or the running executable was removed, only the directory is resolved.
+		var dir string
+		dir, err = filepath.EvalSymlinks(filepath.Dir(exePath))
+		resolved = filepath.Join(dir, filepath.Base(exePath))
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path %s -> %w", exePath, err)
+	}
+
+	return resolved, nil
+}
+
+// executablePath returns the path of the current executable, without the " (deleted)" marker linux appends once it has been removed or replaced.
+// It falls back to the first argument of the process when it can't be read from the system (e.g. without /proc in a container).
+func executablePath() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		exePath, err = argsExecutable()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return strings.TrimSuffix(exePath, " (deleted)"), nil
+}
+
+// argsExecutable resolves the executable path from os.Args[0], looking it up in PATH if it has no directory.
+func argsExecutable() (string, error) {
+	if len(os.Args) == 0 || os.Args[0] == "" {
+		return "", errors.New("no process argument to find the executable from")
+	}
+
+	arg := os.Args[0]
+	if !strings.ContainsRune(arg, os.PathSeparator) && !strings.ContainsRune(arg, '/') {
+		return exec.LookPath(arg)
+	}
+
+	return filepath.Abs(arg)
+}
+
+// checkWritable makes sure the binary at exePath can be replaced by the current user.
+// The install only renames files, so it is the directory that must be writable: the binary itself may be busy, being executed.
+func (u *Updater) checkWritable(exePath string) error {
+	dir := filepath.Dir(exePath)
+
+	name := filepath.Join(dir, fmt.Sprintf(".write-check-%d", os.Getpid()))
+	f, err := u.fsys.Create(name, false, 0600)
+	if err != nil {
+		return fmt.Errorf("%w: %s can't be replaced as %s is not writable, try running with elevated privileges -> %w", ErrNotWritable, exePath, dir, err)
+	}
+	f.Close()
+	u.fsys.Remove(name)
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package selfupdater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WithTempDir sets the directory release assets are downloaded to.
+// By default, they are downloaded next to the binary to replace so that installing them is a simple rename on the same filesystem,
+// falling back to os.TempDir if that directory isn't writable.
+func WithTempDir(dir string) UpdaterOpts {
+	return func(u *Updater) {
+		u.tempDir = dir
+	}
+}
+
+// tempDirCandidates returns the directories the release asset may be downloaded to, by order of preference.
+func (u *Updater) tempDirCandidates() []string {
+	name := fmt.Sprintf(".%s-update", u.Repo)
+
+	if u.tempDir != "" {
+		return []string{filepath.Join(u.tempDir, name)}
+	}
+
+	candidates := []string{filepath.Join(os.TempDir(), name)}
+	if exePath, err := u.resolveExePath(); err == nil {
+		candidates = append([]string{filepath.Join(filepath.Dir(exePath), name)}, candidates...)
+	}
+
+	return candidates
+}
+
+// prepareTempDir creates the directory the release asset is downloaded to and stores it in u.tmpDir.
+func (u *Updater) prepareTempDir() error {
+	if u.customFileSystem() {
+		dir, err := u.fsys.TempDir()
+		if err != nil {
+			return fmt.Errorf("%w: failed to create temp download directory -> %w", ErrDownloadFailed, err)
+		}
+		u.tmpDir = dir
+		return nil
+	}
+
+	var err error
+	for _, dir := range u.tempDirCandidates() {
+		if err = os.MkdirAll(dir, 0755); err == nil {
+			u.tmpDir = dir
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: failed to create temp download directory -> %w", ErrDownloadFailed, err)
+}
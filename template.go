@@ -0,0 +1,89 @@
+package selfupdater
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// AssetTemplateData is the data an asset name template is rendered with, see [WithAssetTemplate].
+type AssetTemplateData struct {
+	// ProjectName is the repository name.
+	ProjectName string
+	// Version is the version to install, without the `v` prefix.
+	Version string
+	// OS is the operating system, like `linux`, `darwin` or `windows`. Os is the same, for GoReleaser templates.
+	OS string
+	Os string
+	// Arch is the architecture, like `amd64`, `arm64` or `arm`.
+	Arch string
+	// Arm is the 32-bit ARM version, like `6` or `7`, empty if unknown or not on ARM.
+	Arm string
+	// Ext is `.exe` on windows, empty elsewhere.
+	Ext string
+}
+
+var templateFuncs = template.FuncMap{
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// WithAssetTemplate makes the [Updater] download the asset named exactly after the given template, like `myapp_{{.Version}}_{{.OS}}_{{.Arch}}.tar.gz`
+// (see [AssetTemplateData] for the available fields, and the `title`, `upper` and `lower` functions).
+// The template is also rendered with the common aliases of the os and arch (like `macos` or `x86_64`), any of the names matches.
+// An invalid template makes every call fail with [ErrInvalidOption] (see [Updater.Err]).
+func WithAssetTemplate(tmpl string) UpdaterOpts {
+	return func(u *Updater) {
+		u.assetTemplate = tmpl
+	}
+}
+
+func parseAssetTemplate(tmpl string) (*template.Template, error) {
+	if tmpl == "" {
+		return nil, nil
+	}
+
+	parsed, err := template.New("asset").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed asset template %q -> %w", ErrInvalidOption, tmpl, err)
+	}
+
+	return parsed, nil
+}
+
+// templateNames renders the asset template for the version to install, with every alias of the os and arch.
+func (u *Updater) templateNames() ([]string, error) {
+	data := AssetTemplateData{
+		ProjectName: u.Repo,
+		Version:     u.target.String(),
+		Arm:         u.goarm,
+	}
+	if u.goos == "windows" {
+		data.Ext = ".exe"
+	}
+
+	var names []string
+	for _, goos := range aliases(osAliases, u.goos) {
+		for _, goarch := range aliases(archAliases, u.goarch) {
+			data.OS, data.Os, data.Arch = goos, goos, goarch
+
+			var name strings.Builder
+			if err := u.assetTmpl.Execute(&name, data); err != nil {
+				return nil, fmt.Errorf("%w: failed to render asset template -> %w", ErrAssetNotFound, err)
+			}
+
+			if !slices.Contains(names, name.String()) {
+				names = append(names, name.String())
+			}
+		}
+	}
+
+	return names, nil
+}
@@ -0,0 +1,32 @@
+package selfupdater
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout bounds every operation of the [Updater] (checking, downloading and installing an update) to the given duration.
+// The deadline is derived from the context given with [WithContext] (context.Background by default) each time an operation starts,
+// a download interrupted by the deadline is removed instead of being kept for resumption.
+func WithTimeout(d time.Duration) UpdaterOpts {
+	return func(u *Updater) {
+		u.timeout = d
+	}
+}
+
+// withDeadline applies the configured timeout to u.ctx until the returned function is called.
+// Nested operations (like [Updater.Update] called by [Updater.CheckAndUpdate]) share the deadline of the outermost one.
+func (u *Updater) withDeadline() func() {
+	if u.timeout <= 0 || u.cancel != nil {
+		return func() {}
+	}
+
+	base := u.ctx
+	u.ctx, u.cancel = context.WithTimeout(base, u.timeout)
+
+	return func() {
+		u.cancel()
+		u.cancel = nil
+		u.ctx = base
+	}
+}
@@ -0,0 +1,49 @@
+package selfupdater
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// WithTokenSource makes the [Updater] authenticate every github API call with the token returned by source, for tokens which expire and get rotated
+// like fine-grained personal access tokens or GitHub App installation tokens. source is called once per request: it is up to it to cache the token
+// until it nears its expiry. It takes precedence over [WithToken]. If source fails, the request fails with its error.
+func WithTokenSource(source func() (string, error)) UpdaterOpts {
+	return func(u *Updater) {
+		u.tokenSource = source
+	}
+}
+
+// tokenTransport sets the Authorization header of each request with a token fetched from source.
+type tokenTransport struct {
+	source func() (string, error)
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get github token -> %w", err)
+	}
+
+	// a RoundTripper must not modify the given request.
+	req = req.Clone(req.Context())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// withTokenSource returns a copy of client authenticating its requests with the tokens of source.
+func withTokenSource(client *http.Client, source func() (string, error)) *http.Client {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	authenticated := *client
+	authenticated.Transport = &tokenTransport{source: source, base: base}
+
+	return &authenticated
+}
@@ -0,0 +1,55 @@
+package selfupdater
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// versionCheckTimeout bounds the run of the version command, which is expected to exit right away.
+const versionCheckTimeout = 30 * time.Second
+
+// WithVersionCheckCommand makes the [Updater] run the new binary with args once installed (e.g. `--version`) and parse its combined output with parse,
+// rolling back if the reported version is not the one of the release (build metadata aside), like when the wrong asset was published.
+// It runs whether the test launch is enabled or not (see [WithLaunchVerification]), with the environment of the test launch.
+// It is skipped by [Updater.InstallFromReader], as there is no release version to compare with.
+func WithVersionCheckCommand(args []string, parse func(output string) (semver.Version, error)) UpdaterOpts {
+	return func(u *Updater) {
+		u.versionArgs = args
+		u.versionParse = parse
+	}
+}
+
+// checkInstalledVersion runs the version command of the binary at exePath, if set, and compares the result to the target version.
+func (u *Updater) checkInstalledVersion(exePath string) error {
+	if u.versionParse == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(u.ctx, versionCheckTimeout)
+	defer cancel()
+
+	_, env := u.relaunchArgsAndEnv()
+	cmd := exec.CommandContext(ctx, exePath, u.versionArgs...)
+	cmd.Env = env
+
+	u.logger.Info("checking the version of the new binary", "path", exePath, "args", u.versionArgs)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: failed to run the version command -> %w", ErrVersionMismatch, err)
+	}
+
+	version, err := u.versionParse(string(output))
+	if err != nil {
+		return fmt.Errorf("%w: failed to parse the version command output %q -> %w", ErrVersionMismatch, output, err)
+	}
+
+	if compareVersions(version, u.target) != 0 {
+		return fmt.Errorf("%w: installed binary reports %s, expected %s", ErrVersionMismatch, version, u.target)
+	}
+
+	return nil
+}